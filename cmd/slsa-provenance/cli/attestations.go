@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/philips-labs/slsa-provenance-action/cmd/slsa-provenance/cli/options"
+	"github.com/philips-labs/slsa-provenance-action/pkg/oci"
+)
+
+// Attestations creates an instance of *cobra.Command to work with attestations attached to images
+func Attestations() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "attestations",
+		Short: "Find attestations attached to images using subcommands",
+	}
+
+	cmd.AddCommand(
+		AttestationsList(),
+		AttestationsDownload(),
+	)
+
+	return cmd
+}
+
+// AttestationsList creates an instance of *cobra.Command to list the attestations attached to an image
+func AttestationsList() *cobra.Command {
+	o := &options.AttestationsListOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "list <image>",
+		Short: "List the attestations attached to an image",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			attestations, err := oci.FetchAttestations(args[0], o.GetRegistryClientOpts(cmd.Context())...)
+			if err != nil {
+				return fmt.Errorf("failed to fetch attestations: %w", err)
+			}
+
+			return printAttestations(cmd.OutOrStdout(), args[0], attestations, o.OutputJSON)
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
+
+// AttestationsDownload creates an instance of *cobra.Command to download the attestations attached to an image
+func AttestationsDownload() *cobra.Command {
+	o := &options.AttestationsDownloadOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "download <image>",
+		Short: "Download the attestations attached to an image",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outputPath, err := o.GetOutputPath()
+			if err != nil {
+				return err
+			}
+
+			attestations, err := oci.FetchAttestations(args[0], o.GetRegistryClientOpts(cmd.Context())...)
+			if err != nil {
+				return fmt.Errorf("failed to fetch attestations: %w", err)
+			}
+
+			var b strings.Builder
+			for _, a := range attestations {
+				line, err := json.Marshal(a.Envelope)
+				if err != nil {
+					return fmt.Errorf("failed to marshal attestation: %w", err)
+				}
+				b.Write(line)
+				b.WriteString("\n")
+			}
+			if err := os.WriteFile(outputPath, []byte(b.String()), 0644); err != nil {
+				return fmt.Errorf("failed to write attestations: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Saved %d attestation(s) to %s\n", len(attestations), outputPath)
+			return nil
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
+
+type attestationSummary struct {
+	Layout        string   `json:"layout"`
+	Ref           string   `json:"ref"`
+	PredicateType string   `json:"predicateType"`
+	BuilderID     string   `json:"builderId,omitempty"`
+	Subjects      []string `json:"subjects"`
+}
+
+func printAttestations(out io.Writer, image string, attestations []oci.Attestation, outputJSON bool) error {
+	summaries := make([]attestationSummary, len(attestations))
+	for i, a := range attestations {
+		subjects := make([]string, len(a.Statement.Subject))
+		for j, s := range a.Statement.Subject {
+			subjects[j] = fmt.Sprintf("%s %s", s.Name, formatDigests(s.Digest))
+		}
+
+		summaries[i] = attestationSummary{
+			Layout:        a.Layout,
+			Ref:           a.Ref,
+			PredicateType: a.Statement.PredicateType,
+			BuilderID:     a.Statement.BuilderID(),
+			Subjects:      subjects,
+		}
+	}
+
+	if outputJSON {
+		j, err := json.MarshalIndent(summaries, "", "  ")
+		if err != nil {
+			return fmt.Errorf("unable to generate JSON from attestations: %w", err)
+		}
+		fmt.Fprintln(out, string(j))
+		return nil
+	}
+
+	fmt.Fprintf(out, "Found %d attestation(s) for %s\n", len(summaries), image)
+	for _, s := range summaries {
+		fmt.Fprintf(out, "\n%s (%s)\n", s.Ref, s.Layout)
+		fmt.Fprintf(out, "  predicateType: %s\n", s.PredicateType)
+		if s.BuilderID != "" {
+			fmt.Fprintf(out, "  builderId:     %s\n", s.BuilderID)
+		}
+		fmt.Fprintln(out, "  subjects:")
+		for _, subject := range s.Subjects {
+			fmt.Fprintf(out, "    %s\n", subject)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package cli_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/philips-labs/slsa-provenance-action/cmd/slsa-provenance/cli"
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+)
+
+func TestAttestationsCli(t *testing.T) {
+	assert := assert.New(t)
+
+	tmp := t.TempDir()
+	provenanceFile := path.Join(tmp, "provenance.json")
+	attestationsFile := path.Join(tmp, "attestations.intoto.jsonl")
+
+	s := httptest.NewServer(registry.New())
+	t.Cleanup(s.Close)
+	repo := fmt.Sprintf("%s/philips-labs/slsa-provenance", strings.TrimPrefix(s.URL, "http://"))
+	img, err := random.Image(1024, 1)
+	if !assert.NoError(err) {
+		return
+	}
+	if !assert.NoError(crane.Push(img, repo+":v0.1.0")) {
+		return
+	}
+	imgDigest, err := img.Digest()
+	assert.NoError(err)
+
+	image := repo + ":v0.1.0"
+	output, err := executeCommand(cli.AttestationsList(), image)
+	assert.NoError(err)
+	assert.Equal(fmt.Sprintf("Found 0 attestation(s) for %s\n", image), output)
+
+	for _, layout := range []string{"tag", "referrers"} {
+		_, err := executeCommand(cli.OCI(),
+			"--github-context", base64.StdEncoding.EncodeToString([]byte(githubContext)),
+			"--runner-context", base64.StdEncoding.EncodeToString([]byte(runnerContext)),
+			"--repository", repo,
+			"--digest", imgDigest.String(),
+			"--tags", "v0.1.0",
+			"--output-path", provenanceFile,
+			"--attach", layout,
+		)
+		if !assert.NoError(err) {
+			return
+		}
+	}
+
+	output, err = executeCommand(cli.AttestationsList(), image)
+	assert.NoError(err)
+	assert.Contains(output, fmt.Sprintf("Found 2 attestation(s) for %s\n", image))
+	assert.Contains(output, fmt.Sprintf("%s:sha256-%s.att (tag)\n", repo, imgDigest.Hex))
+	assert.Contains(output, "(referrers)\n")
+	assert.Contains(output, "  predicateType: https://slsa.dev/provenance/v0.2\n")
+	assert.Contains(output, "  builderId:     https://github.com/philips-labs/slsa-provenance-action/Attestations/")
+	assert.Contains(output, fmt.Sprintf("    %s:v0.1.0 sha256:%s\n", repo, imgDigest.Hex))
+
+	output, err = executeCommand(cli.AttestationsList(), "--json", image)
+	assert.NoError(err)
+	var summaries []map[string]interface{}
+	assert.NoError(json.Unmarshal([]byte(output), &summaries))
+	if assert.Len(summaries, 2) {
+		assert.Equal("tag", summaries[0]["layout"])
+		assert.Equal("referrers", summaries[1]["layout"])
+	}
+
+	output, err = executeCommand(cli.AttestationsDownload(), "--output-path", attestationsFile, image)
+	assert.NoError(err)
+	assert.Equal(fmt.Sprintf("Saved 2 attestation(s) to %s\n", attestationsFile), output)
+	content, err := os.ReadFile(attestationsFile)
+	assert.NoError(err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if assert.Len(lines, 2) {
+		for _, line := range lines {
+			var env intoto.Envelope
+			assert.NoError(json.Unmarshal([]byte(line), &env))
+			assert.Equal(intoto.PayloadType, env.PayloadType)
+		}
+	}
+
+	_, err = executeCommand(cli.AttestationsDownload(), "--output-path", "", image)
+	assert.EqualError(err, cli.RequiredFlagError("output-path").Error())
+
+	_, err = executeCommand(cli.AttestationsList())
+	assert.EqualError(err, "accepts 1 arg(s), received 0")
+
+	_, err = executeCommand(cli.AttestationsList(), repo+":non-existing")
+	assert.ErrorContains(err, "failed to fetch attestations: ")
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/philips-labs/slsa-provenance-action/cmd/slsa-provenance/cli/options"
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+)
+
+// Checksums creates an instance of *cobra.Command to generate provenance from checksums files
+func Checksums() *cobra.Command {
+	o := &options.ChecksumsOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "checksums",
+		Short: "Generate provenance using the subjects of existing checksums files",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			checksumsFiles, err := o.GetChecksumsFiles()
+			if err != nil {
+				return err
+			}
+			outputPath, err := o.GetOutputPath()
+			if err != nil {
+				return err
+			}
+			outputFormat, err := o.GetOutputFormat()
+			if err != nil {
+				return err
+			}
+			signer, err := o.GetSigner()
+			if err != nil {
+				return err
+			}
+
+			env, err := newProvenanceEnvironment(&o.GenerateOptions)
+			if err != nil {
+				return err
+			}
+
+			materials, err := o.GetExtraMaterials()
+			if err != nil {
+				return err
+			}
+
+			subjecter := intoto.NewChecksumsSubjecter(checksumsFiles, o.GetChecksumsSubjecterOptions()...)
+			stmt, err := env.GenerateProvenanceStatement(cmd.Context(), subjecter, materials...)
+			if err != nil {
+				return fmt.Errorf("failed to generate provenance: %w", err)
+			}
+			if ro.Verbose {
+				printSubjects(cmd.OutOrStdout(), stmt.Subject)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Saving provenance to %s\n", outputPath)
+
+			return persistProvenance(cmd.Context(), env, stmt, outputFormat, outputPath, signer)
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
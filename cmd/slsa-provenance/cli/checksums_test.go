@@ -0,0 +1,101 @@
+package cli_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/philips-labs/slsa-provenance-action/cmd/slsa-provenance/cli"
+)
+
+func TestGenerateChecksumsCliOptions(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	rootDir := path.Join(path.Dir(filename), "../../..")
+	provenanceFile := path.Join(rootDir, "bin/unittest-checksums-provenance.json")
+	checksumsDir := path.Join(rootDir, "test-data/checksums")
+
+	base64GitHubContext := base64.StdEncoding.EncodeToString([]byte(githubContext))
+	base64RunnerContext := base64.StdEncoding.EncodeToString([]byte(runnerContext))
+
+	testCases := []struct {
+		name      string
+		err       error
+		arguments []string
+	}{
+		{
+			name:      "without commandline flags",
+			err:       cli.RequiredFlagError("checksums-file"),
+			arguments: make([]string, 0),
+		},
+		{
+			name: "gnu and bsd checksums files",
+			arguments: []string{
+				"--checksums-file",
+				path.Join(checksumsDir, "dist/SHA256SUMS"),
+				"--checksums-file",
+				path.Join(checksumsDir, "dist/SHA512SUMS.bsd"),
+				"--github-context",
+				base64GitHubContext,
+				"--runner-context",
+				base64RunnerContext,
+				"--output-path",
+				provenanceFile,
+			},
+		},
+		{
+			name: "verify artifacts",
+			arguments: []string{
+				"--checksums-file",
+				path.Join(checksumsDir, "dist/SHA256SUMS"),
+				"--verify-artifacts",
+				"--github-context",
+				base64GitHubContext,
+				"--runner-context",
+				base64RunnerContext,
+				"--output-path",
+				provenanceFile,
+			},
+		},
+		{
+			name: "verify tampered artifacts",
+			err:  fmt.Errorf("failed to generate provenance: checksums verification failed: sha256 checksum mismatch for app, expected 0fe830daafaf34656a6a130086057015f3e66cee6bd3c8de8c0f488bf2a8a827, got efe830daafaf34656a6a130086057015f3e66cee6bd3c8de8c0f488bf2a8a827"),
+			arguments: []string{
+				"--checksums-file",
+				path.Join(checksumsDir, "SHA256SUMS.tampered"),
+				"--verify-artifacts",
+				"--artifact-path",
+				path.Join(checksumsDir, "dist"),
+				"--github-context",
+				base64GitHubContext,
+				"--runner-context",
+				base64RunnerContext,
+				"--output-path",
+				provenanceFile,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			assert := assert.New(tt)
+
+			output, err := executeCommand(cli.Checksums(), tc.arguments...)
+			defer func() {
+				_ = os.Remove(provenanceFile)
+			}()
+
+			if tc.err != nil {
+				assert.EqualError(err, tc.err.Error())
+			} else {
+				assert.NoError(err)
+				assert.Contains(output, "Saving provenance to")
+				assert.FileExists(provenanceFile)
+			}
+		})
+	}
+}
@@ -36,6 +36,10 @@ func New() *cobra.Command {
 
 	cmd.AddCommand(Version())
 	cmd.AddCommand(Generate())
+	cmd.AddCommand(Verify())
+	cmd.AddCommand(Policy())
+	cmd.AddCommand(VSA())
+	cmd.AddCommand(Attestations())
 
 	return cmd
 }
@@ -2,6 +2,7 @@ package cli_test
 
 import (
 	"bytes"
+	"testing"
 
 	"github.com/spf13/cobra"
 )
@@ -277,3 +278,10 @@ func stringPointer(s string) *string {
 func boolPointer(b bool) *bool {
 	return &b
 }
+
+// withoutCI clears the environment variables used to detect the CI provider, so the tests
+// behave the same when running in a CI job
+func withoutCI(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("GITLAB_CI", "")
+}
@@ -1,12 +1,15 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"io"
 
+	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/spf13/cobra"
 
 	"github.com/philips-labs/slsa-provenance-action/cmd/slsa-provenance/cli/options"
-	"github.com/philips-labs/slsa-provenance-action/pkg/github"
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
 	"github.com/philips-labs/slsa-provenance-action/pkg/oci"
 )
 
@@ -22,13 +25,16 @@ func OCI() *cobra.Command {
 			if err != nil {
 				return err
 			}
-
-			gh, err := o.GetGitHubContext()
+			outputFormat, err := o.GetOutputFormat()
+			if err != nil {
+				return err
+			}
+			signer, err := o.GetSigner()
 			if err != nil {
 				return err
 			}
 
-			runner, err := o.GetRunnerContext()
+			env, err := newProvenanceEnvironment(&o.GenerateOptions)
 			if err != nil {
 				return err
 			}
@@ -53,23 +59,53 @@ func OCI() *cobra.Command {
 				return err
 			}
 
-			opts := o.GetRegistryClientOpts(cmd.Context())
-			subjecter := oci.NewContainerSubjecter(repo, digest, tags, opts...)
+			digestAlgorithms, err := o.GetDigestAlgorithms()
+			if err != nil {
+				return err
+			}
 
-			env := &github.Environment{
-				Context: gh,
-				Runner:  runner,
+			platforms, err := o.GetPlatforms()
+			if err != nil {
+				return err
 			}
-			stmt, err := env.GenerateProvenanceStatement(cmd.Context(), subjecter)
+
+			attach, err := o.GetAttach()
 			if err != nil {
-				return fmt.Errorf("failed to generate provenance: %w", err)
+				return err
+			}
+
+			subjecter, err := o.GetSubjecter(cmd.Context(), repo, digest, tags)
+			if err != nil {
+				return err
+			}
+			subjecter = subjecter.WithDigestAlgorithms(digestAlgorithms...)
+			if o.GetPlatformManifests() {
+				subjecter = subjecter.WithPlatformManifests(platforms...)
 			}
 
-			stmt.Predicate.Materials = append(stmt.Predicate.Materials, materials...)
+			stmt, err := env.GenerateProvenanceStatement(cmd.Context(), subjecter, materials...)
+			if err != nil {
+				return fmt.Errorf("failed to generate provenance: %w", err)
+			}
 
 			fmt.Fprintf(cmd.OutOrStdout(), "Saving provenance to %s\n", outputPath)
 
-			return env.PersistProvenanceStatement(cmd.Context(), stmt, outputPath)
+			if attach == "" {
+				return persistProvenance(cmd.Context(), env, stmt, outputFormat, outputPath, signer)
+			}
+
+			imageRef, err := o.GetImageReference()
+			if err != nil {
+				return err
+			}
+			p := attachingPersister{
+				provenancePersister: env,
+				subject:             imageRef,
+				layout:              attach,
+				options:             o.GetRegistryClientOpts(cmd.Context()),
+				out:                 cmd.OutOrStdout(),
+			}
+			return persistProvenance(cmd.Context(), p, stmt, options.OutputFormatDSSE, outputPath, signer)
 		},
 	}
 
@@ -77,3 +113,27 @@ func OCI() *cobra.Command {
 
 	return cmd
 }
+
+// attachingPersister attaches the provenance envelope to the image in the registry after writing it
+type attachingPersister struct {
+	provenancePersister
+	subject string
+	layout  string
+	options []crane.Option
+	out     io.Writer
+}
+
+// PersistProvenanceEnvelope writes the provenance envelope at the given path and attaches it to the image
+func (p attachingPersister) PersistProvenanceEnvelope(ctx context.Context, env *intoto.Envelope, path string) error {
+	if err := p.provenancePersister.PersistProvenanceEnvelope(ctx, env, path); err != nil {
+		return err
+	}
+
+	ref, err := oci.AttachEnvelope(p.subject, env, p.layout, p.options...)
+	if err != nil {
+		return fmt.Errorf("failed to attach provenance: %w", err)
+	}
+	fmt.Fprintf(p.out, "Attached provenance to %s\n", ref)
+
+	return nil
+}
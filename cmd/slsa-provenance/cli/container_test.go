@@ -2,17 +2,33 @@ package cli_test
 
 import (
 	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
 	"os"
 	"path"
 	"runtime"
+	"strings"
 	"testing"
 
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/philips-labs/slsa-provenance-action/cmd/slsa-provenance/cli"
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
 )
 
 func TestGenerateContainerCliOptions(t *testing.T) {
+	withoutCI(t)
+
 	_, filename, _, _ := runtime.Caller(0)
 	provenanceFile := path.Join(path.Dir(filename), "provenance.json")
 
@@ -73,6 +89,38 @@ func TestGenerateContainerCliOptions(t *testing.T) {
 				"v0.4.0,33ba3da2213c83ce02df0f2f6ba925ec79037f9d",
 			},
 		},
+		{
+			name: "invalid platform given",
+			err:  errors.New(`invalid platform "linux/arm/v7/extra": too many slashes in platform spec: linux/arm/v7/extra`),
+			arguments: []string{
+				"--github-context",
+				base64GitHubContext,
+				"--runner-context",
+				base64RunnerContext,
+				"--repository",
+				"ghcr.io/philips-labs/slsa-provenance",
+				"--digest",
+				"sha256:194b471a878add368bf02a7935fa099024576c029491bcefaeb87f81efa093a3",
+				"--platform",
+				"linux/arm/v7/extra",
+			},
+		},
+		{
+			name: "invalid attach layout given",
+			err:  errors.New(`unsupported attach layout "sidecar", supported layouts: tag, referrers`),
+			arguments: []string{
+				"--github-context",
+				base64GitHubContext,
+				"--runner-context",
+				base64RunnerContext,
+				"--repository",
+				"ghcr.io/philips-labs/slsa-provenance",
+				"--digest",
+				"sha256:194b471a878add368bf02a7935fa099024576c029491bcefaeb87f81efa093a3",
+				"--attach",
+				"sidecar",
+			},
+		},
 		{
 			name: "all flags given",
 			err:  nil,
@@ -114,3 +162,181 @@ func TestGenerateContainerCliOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateContainerAttach(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	provenanceFile := path.Join(path.Dir(filename), "provenance.json")
+
+	base64GitHubContext := base64.StdEncoding.EncodeToString([]byte(githubContext))
+	base64RunnerContext := base64.StdEncoding.EncodeToString([]byte(runnerContext))
+
+	s := httptest.NewServer(registry.New())
+	t.Cleanup(s.Close)
+	repo := fmt.Sprintf("%s/philips-labs/slsa-provenance", strings.TrimPrefix(s.URL, "http://"))
+	img, err := random.Image(1024, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.NoError(t, crane.Push(img, repo+":v0.1.0")) {
+		return
+	}
+	imgDigest, err := img.Digest()
+	assert.NoError(t, err)
+	other, err := random.Image(1024, 1)
+	assert.NoError(t, err)
+	assert.NoError(t, crane.Push(other, repo+":v0.2.0"))
+	assert.NoError(t, crane.Push(other, repo+":v0.3.0"))
+	otherDigest, err := other.Digest()
+	assert.NoError(t, err)
+
+	for _, layout := range []string{"tag", "referrers"} {
+		t.Run(layout, func(tt *testing.T) {
+			assert := assert.New(tt)
+
+			output, err := executeCommand(cli.OCI(),
+				"--github-context", base64GitHubContext,
+				"--runner-context", base64RunnerContext,
+				"--repository", repo,
+				"--digest", imgDigest.String(),
+				"--tags", "v0.1.0",
+				"--output-path", provenanceFile,
+				"--attach", layout,
+			)
+			defer func() {
+				_ = os.Remove(provenanceFile)
+			}()
+
+			assert.NoError(err)
+			assert.Contains(output, "Attached provenance to "+repo)
+
+			content, err := os.ReadFile(provenanceFile)
+			assert.NoError(err)
+			var env intoto.Envelope
+			assert.NoError(json.Unmarshal(content, &env))
+			assert.Equal(intoto.PayloadType, env.PayloadType)
+		})
+	}
+
+	_, err = crane.Manifest(fmt.Sprintf("%s:sha256-%s.att", repo, imgDigest.Hex))
+	assert.NoError(t, err)
+
+	pinned := fmt.Sprintf("%s@%s", repo, imgDigest)
+	output, err := executeCommand(cli.OCI(),
+		"--github-context", base64GitHubContext,
+		"--runner-context", base64RunnerContext,
+		"--repository", pinned,
+		"--output-path", provenanceFile,
+		"--attach", "tag",
+	)
+	defer func() {
+		_ = os.Remove(provenanceFile)
+	}()
+	assert.NoError(t, err)
+	assert.Contains(t, output, fmt.Sprintf("Attached provenance to %s:sha256-%s.att", repo, imgDigest.Hex))
+
+	content, err := os.ReadFile(provenanceFile)
+	assert.NoError(t, err)
+	var env intoto.Envelope
+	assert.NoError(t, json.Unmarshal(content, &env))
+	stmt, err := env.Statement()
+	assert.NoError(t, err)
+	assert.Equal(t, []intoto.Subject{{Name: pinned, Digest: intoto.DigestSet{"sha256": imgDigest.Hex}}}, stmt.Subject)
+
+	_, err = executeCommand(cli.OCI(),
+		"--github-context", base64GitHubContext,
+		"--runner-context", base64RunnerContext,
+		"--repository", repo,
+		"--digest", imgDigest.String(),
+		"--tags", "v0.1.0,v0.2.0,v0.3.0",
+		"--output-path", provenanceFile,
+	)
+	assert.EqualError(t, err, fmt.Sprintf("failed to generate provenance: did not get expected digest %s for %s:v0.2.0 (got %s), %s:v0.3.0 (got %s)", imgDigest, repo, otherDigest, repo, otherDigest))
+}
+
+func TestGenerateContainerOffline(t *testing.T) {
+	tmp := t.TempDir()
+	provenanceFile := path.Join(tmp, "provenance.json")
+	repo := "ghcr.io/philips-labs/slsa-provenance"
+
+	base64GitHubContext := base64.StdEncoding.EncodeToString([]byte(githubContext))
+	base64RunnerContext := base64.StdEncoding.EncodeToString([]byte(runnerContext))
+
+	img, err := random.Image(1024, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	layoutDir := path.Join(tmp, "layout")
+	_, err = layout.Write(layoutDir, mutate.AppendManifests(empty.Index, mutate.IndexAddendum{Add: img}))
+	assert.NoError(t, err)
+	imgDigest, err := img.Digest()
+	assert.NoError(t, err)
+
+	tarballFile := path.Join(tmp, "image.tar")
+	tag, err := name.NewTag(repo + ":v0.1.0")
+	assert.NoError(t, err)
+	assert.NoError(t, tarball.WriteToFile(tarballFile, tag, img))
+	tarballImg, err := tarball.ImageFromPath(tarballFile, nil)
+	assert.NoError(t, err)
+	tarballDigest, err := tarballImg.Digest()
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		err       error
+		digest    string
+		arguments []string
+	}{
+		{
+			name:      "oci layout",
+			digest:    imgDigest.Hex,
+			arguments: []string{"--oci-layout", layoutDir},
+		},
+		{
+			name:      "oci layout with digest",
+			digest:    imgDigest.Hex,
+			arguments: []string{"--oci-layout", layoutDir, "--digest", imgDigest.String()},
+		},
+		{
+			name:      "docker tarball",
+			digest:    tarballDigest.Hex,
+			arguments: []string{"--tarball", tarballFile},
+		},
+		{
+			name:      "oci layout and docker tarball",
+			err:       errors.New("only one of the flags oci-layout and tarball can be given"),
+			arguments: []string{"--oci-layout", layoutDir, "--tarball", tarballFile},
+		},
+		{
+			name:      "attach without digest",
+			err:       cli.RequiredFlagError("digest"),
+			arguments: []string{"--oci-layout", layoutDir, "--attach", "tag"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			assert := assert.New(tt)
+
+			output, err := executeCommand(cli.OCI(), append([]string{
+				"--github-context", base64GitHubContext,
+				"--runner-context", base64RunnerContext,
+				"--repository", repo,
+				"--tags", "v0.1.0",
+				"--output-path", provenanceFile,
+			}, tc.arguments...)...)
+
+			if tc.err != nil {
+				assert.EqualError(err, tc.err.Error())
+				return
+			}
+			assert.NoError(err)
+			assert.Contains(output, "Saving provenance to")
+
+			content, err := os.ReadFile(provenanceFile)
+			assert.NoError(err)
+			var stmt intoto.Statement
+			assert.NoError(json.Unmarshal(content, &stmt))
+			assert.Equal([]intoto.Subject{{Name: repo + ":v0.1.0", Digest: intoto.DigestSet{"sha256": tc.digest}}}, stmt.Subject)
+		})
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/philips-labs/slsa-provenance-action/cmd/slsa-provenance/cli/options"
-	"github.com/philips-labs/slsa-provenance-action/pkg/github"
 	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
 )
 
@@ -26,13 +25,16 @@ func Files() *cobra.Command {
 			if err != nil {
 				return err
 			}
-
-			gh, err := o.GetGitHubContext()
+			outputFormat, err := o.GetOutputFormat()
+			if err != nil {
+				return err
+			}
+			signer, err := o.GetSigner()
 			if err != nil {
 				return err
 			}
 
-			runner, err := o.GetRunnerContext()
+			env, err := newProvenanceEnvironment(&o.GenerateOptions)
 			if err != nil {
 				return err
 			}
@@ -42,20 +44,29 @@ func Files() *cobra.Command {
 				return err
 			}
 
-			env := &github.Environment{
-				Context: gh,
-				Runner:  runner,
+			subjecterOpts, err := o.GetFilePathSubjecterOptions()
+			if err != nil {
+				return err
 			}
 
-			subjecter := intoto.NewFilePathSubjecter(artifactPath)
+			digestAlgorithms, err := o.GetDigestAlgorithms()
+			if err != nil {
+				return err
+			}
+			subjecterOpts = append(subjecterOpts, intoto.WithDigestAlgorithms(digestAlgorithms...))
+
+			subjecter := intoto.NewFilePathSubjecter(artifactPath, subjecterOpts...)
 			stmt, err := env.GenerateProvenanceStatement(cmd.Context(), subjecter, materials...)
 			if err != nil {
 				return fmt.Errorf("failed to generate provenance: %w", err)
 			}
+			if ro.Verbose {
+				printSubjects(cmd.OutOrStdout(), stmt.Subject)
+			}
 
 			fmt.Fprintf(cmd.OutOrStdout(), "Saving provenance to %s\n", outputPath)
 
-			return env.PersistProvenanceStatement(cmd.Context(), stmt, outputPath)
+			return persistProvenance(cmd.Context(), env, stmt, outputFormat, outputPath, signer)
 		},
 	}
 
@@ -18,6 +18,8 @@ const (
 )
 
 func TestGenerateFilesCliOptions(t *testing.T) {
+	withoutCI(t)
+
 	_, filename, _, _ := runtime.Caller(0)
 	rootDir := path.Join(path.Dir(filename), "../../..")
 	provenanceFile := path.Join(rootDir, "bin/unittest-provenance.json")
@@ -81,6 +83,235 @@ func TestGenerateFilesCliOptions(t *testing.T) {
 				base64RunnerContext,
 			},
 		},
+		{
+			name: "With predicate version v1",
+			err:  nil,
+			arguments: []string{
+				"--artifact-path",
+				path.Join(rootDir, "bin/slsa-provenance"),
+				"--github-context",
+				base64GitHubContext,
+				"--output-path",
+				provenanceFile,
+				"--runner-context",
+				base64RunnerContext,
+				"--predicate-version",
+				"v1",
+			},
+		},
+		{
+			name: "With unsupported predicate version",
+			err:  fmt.Errorf("unsupported predicate-version \"v0.1\", supported versions: v0.2, v1"),
+			arguments: []string{
+				"--artifact-path",
+				path.Join(rootDir, "bin/slsa-provenance"),
+				"--github-context",
+				base64GitHubContext,
+				"--output-path",
+				provenanceFile,
+				"--runner-context",
+				base64RunnerContext,
+				"--predicate-version",
+				"v0.1",
+			},
+		},
+		{
+			name: "With output format dsse",
+			err:  nil,
+			arguments: []string{
+				"--artifact-path",
+				path.Join(rootDir, "bin/slsa-provenance"),
+				"--github-context",
+				base64GitHubContext,
+				"--output-path",
+				provenanceFile,
+				"--runner-context",
+				base64RunnerContext,
+				"--output-format",
+				"dsse",
+			},
+		},
+		{
+			name: "With unsupported output format",
+			err:  fmt.Errorf("unsupported output-format \"yaml\", supported formats: statement, dsse"),
+			arguments: []string{
+				"--artifact-path",
+				path.Join(rootDir, "bin/slsa-provenance"),
+				"--github-context",
+				base64GitHubContext,
+				"--output-path",
+				provenanceFile,
+				"--runner-context",
+				base64RunnerContext,
+				"--output-format",
+				"yaml",
+			},
+		},
+		{
+			name: "With unsupported ci",
+			err:  fmt.Errorf(`unsupported ci "jenkins", supported ci: github, gitlab`),
+			arguments: []string{
+				"--artifact-path",
+				path.Join(rootDir, "bin/slsa-provenance"),
+				"--ci",
+				"jenkins",
+			},
+		},
+		{
+			name: "With ci gitlab outside of a GitLab CI job",
+			err:  fmt.Errorf("missing GitLab CI variables: CI_PROJECT_URL, CI_COMMIT_SHA, CI_PIPELINE_ID, CI_JOB_ID"),
+			arguments: []string{
+				"--artifact-path",
+				path.Join(rootDir, "bin/slsa-provenance"),
+				"--ci",
+				"gitlab",
+			},
+		},
+		{
+			name: "With signing key",
+			err:  nil,
+			arguments: []string{
+				"--artifact-path",
+				path.Join(rootDir, "bin/slsa-provenance"),
+				"--github-context",
+				base64GitHubContext,
+				"--output-path",
+				provenanceFile,
+				"--runner-context",
+				base64RunnerContext,
+				"--signing-key",
+				path.Join(rootDir, "test-data/keys/ed25519.key"),
+			},
+		},
+		{
+			name: "With encrypted signing key without password",
+			err:  fmt.Errorf("failed to load signing key %s: private key is encrypted, but no password was provided", path.Join(rootDir, "test-data/keys/ecdsa-encrypted.key")),
+			arguments: []string{
+				"--artifact-path",
+				path.Join(rootDir, "bin/slsa-provenance"),
+				"--github-context",
+				base64GitHubContext,
+				"--output-path",
+				provenanceFile,
+				"--runner-context",
+				base64RunnerContext,
+				"--signing-key",
+				path.Join(rootDir, "test-data/keys/ecdsa-encrypted.key"),
+			},
+		},
+		{
+			name: "With include and exclude patterns",
+			err:  nil,
+			arguments: []string{
+				"--artifact-path",
+				path.Join(rootDir, "bin"),
+				"--github-context",
+				base64GitHubContext,
+				"--output-path",
+				provenanceFile,
+				"--runner-context",
+				base64RunnerContext,
+				"--include",
+				"slsa-*",
+				"--exclude",
+				"**/*.json",
+			},
+		},
+		{
+			name: "With invalid include pattern",
+			err:  fmt.Errorf(`invalid glob pattern "slsa-["`),
+			arguments: []string{
+				"--artifact-path",
+				path.Join(rootDir, "bin"),
+				"--github-context",
+				base64GitHubContext,
+				"--output-path",
+				provenanceFile,
+				"--runner-context",
+				base64RunnerContext,
+				"--include",
+				"slsa-[",
+			},
+		},
+		{
+			name: "With concurrency",
+			err:  nil,
+			arguments: []string{
+				"--artifact-path",
+				path.Join(rootDir, "bin"),
+				"--github-context",
+				base64GitHubContext,
+				"--output-path",
+				provenanceFile,
+				"--runner-context",
+				base64RunnerContext,
+				"--concurrency",
+				"2",
+			},
+		},
+		{
+			name: "With invalid concurrency",
+			err:  fmt.Errorf("invalid concurrency -1, must be 0 (number of CPUs) or greater"),
+			arguments: []string{
+				"--artifact-path",
+				path.Join(rootDir, "bin"),
+				"--github-context",
+				base64GitHubContext,
+				"--output-path",
+				provenanceFile,
+				"--runner-context",
+				base64RunnerContext,
+				"--concurrency",
+				"-1",
+			},
+		},
+		{
+			name: "With archive members",
+			err:  nil,
+			arguments: []string{
+				"--artifact-path",
+				path.Join(rootDir, "bin"),
+				"--github-context",
+				base64GitHubContext,
+				"--output-path",
+				provenanceFile,
+				"--runner-context",
+				base64RunnerContext,
+				"--archive-members",
+			},
+		},
+		{
+			name: "With digest algorithms",
+			err:  nil,
+			arguments: []string{
+				"--artifact-path",
+				path.Join(rootDir, "bin/slsa-provenance"),
+				"--github-context",
+				base64GitHubContext,
+				"--output-path",
+				provenanceFile,
+				"--runner-context",
+				base64RunnerContext,
+				"--digest-algorithms",
+				"sha256,sha512,gitoid:blob:sha1",
+			},
+		},
+		{
+			name: "With unsupported digest algorithm",
+			err:  fmt.Errorf(`unsupported digest algorithm "md5", supported algorithms: sha256, sha384, sha512, sha1, blake2b-256, gitoid:blob:sha1`),
+			arguments: []string{
+				"--artifact-path",
+				path.Join(rootDir, "bin/slsa-provenance"),
+				"--github-context",
+				base64GitHubContext,
+				"--output-path",
+				provenanceFile,
+				"--runner-context",
+				base64RunnerContext,
+				"--digest-algorithms",
+				"md5",
+			},
+		},
 		{
 			name: "With extra materials",
 			err:  nil,
@@ -186,3 +417,129 @@ func TestGenerateFilesCliOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateFilesVerbose(t *testing.T) {
+	assert := assert.New(t)
+
+	_, filename, _, _ := runtime.Caller(0)
+	rootDir := path.Join(path.Dir(filename), "../../..")
+	provenanceFile := path.Join(rootDir, "bin/unittest-verbose-provenance.json")
+	defer func() {
+		_ = os.Remove(provenanceFile)
+	}()
+
+	output, err := executeCommand(cli.New(),
+		"generate", "files", "--verbose",
+		"--artifact-path", path.Join(rootDir, "bin"),
+		"--include", "slsa-provenance",
+		"--github-context", base64.StdEncoding.EncodeToString([]byte(githubContext)),
+		"--runner-context", base64.StdEncoding.EncodeToString([]byte(runnerContext)),
+		"--output-path", provenanceFile,
+	)
+	assert.NoError(err)
+	assert.Contains(output, "Hashed 1 artifact(s):\n  slsa-provenance sha256:")
+}
+
+func TestGenerateFilesGitLab(t *testing.T) {
+	assert := assert.New(t)
+
+	_, filename, _, _ := runtime.Caller(0)
+	rootDir := path.Join(path.Dir(filename), "../../..")
+	provenanceFile := path.Join(rootDir, "bin/unittest-gitlab-provenance.json")
+	defer func() {
+		_ = os.Remove(provenanceFile)
+	}()
+
+	withoutCI(t)
+	t.Setenv("GITLAB_CI", "true")
+	t.Setenv("CI_PROJECT_URL", "https://gitlab.com/philips-labs/slsa-provenance-action")
+	t.Setenv("CI_COMMIT_SHA", "c4f679f131dfb7f810fd411ac9475549d1c393df")
+	t.Setenv("CI_COMMIT_BRANCH", "main")
+	t.Setenv("CI_PIPELINE_ID", "1053418211")
+	t.Setenv("CI_JOB_ID", "5052118342")
+	t.Setenv("CI_RUNNER_ID", "12270852")
+
+	output, err := executeCommand(cli.Files(),
+		"--artifact-path", path.Join(rootDir, "bin/slsa-provenance"),
+		"--output-path", provenanceFile,
+	)
+	assert.NoError(err)
+	assert.Contains(output, "Saving provenance to")
+
+	content, err := os.ReadFile(provenanceFile)
+	assert.NoError(err)
+	assert.Contains(string(content), `"id": "https://gitlab.com/philips-labs/slsa-provenance-action/-/runners/12270852"`)
+	assert.Contains(string(content), `"uri": "git+https://gitlab.com/philips-labs/slsa-provenance-action@refs/heads/main"`)
+}
+
+func TestGenerateFilesFromEnv(t *testing.T) {
+	assert := assert.New(t)
+
+	_, filename, _, _ := runtime.Caller(0)
+	rootDir := path.Join(path.Dir(filename), "../../..")
+	provenanceFile := path.Join(rootDir, "bin/unittest-env-provenance.json")
+	defer func() {
+		_ = os.Remove(provenanceFile)
+	}()
+
+	eventPath := path.Join(t.TempDir(), "event.json")
+	assert.NoError(os.WriteFile(eventPath, []byte(`{"inputs":{"release":"v0.1.0"}}`), 0644))
+
+	withoutCI(t)
+	t.Setenv("GITLAB_CI", "true")
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_REPOSITORY", "philips-labs/slsa-provenance-action")
+	t.Setenv("GITHUB_SHA", "c4f679f131dfb7f810fd411ac9475549d1c393df")
+	t.Setenv("GITHUB_REF", "refs/heads/main")
+	t.Setenv("GITHUB_RUN_ID", "1332651620")
+	t.Setenv("GITHUB_EVENT_NAME", "workflow_dispatch")
+	t.Setenv("GITHUB_EVENT_PATH", eventPath)
+	t.Setenv("RUNNER_OS", "Linux")
+
+	output, err := executeCommand(cli.Files(),
+		"--artifact-path", path.Join(rootDir, "bin/slsa-provenance"),
+		"--output-path", provenanceFile,
+	)
+	assert.NoError(err)
+	assert.Contains(output, "Saving provenance to")
+
+	content, err := os.ReadFile(provenanceFile)
+	assert.NoError(err)
+	assert.Contains(string(content), `"id": "https://github.com/philips-labs/slsa-provenance-action/Attestations/GitHubHostedActions@v1"`)
+	assert.Contains(string(content), `"buildInvocationId": "https://github.com/philips-labs/slsa-provenance-action/actions/runs/1332651620"`)
+	assert.Contains(string(content), `"release": "v0.1.0"`)
+
+	t.Setenv("GITHUB_SERVER_URL", "https://github.example.com")
+	t.Setenv("GITHUB_API_URL", "https://github.example.com/api/v3")
+	_, err = executeCommand(cli.Files(),
+		"--artifact-path", path.Join(rootDir, "bin/slsa-provenance"),
+		"--output-path", provenanceFile,
+	)
+	assert.NoError(err)
+
+	content, err = os.ReadFile(provenanceFile)
+	assert.NoError(err)
+	assert.Contains(string(content), `"id": "https://github.example.com/philips-labs/slsa-provenance-action/Attestations/GitHubHostedActions@v1"`)
+	assert.Contains(string(content), `"buildInvocationId": "https://github.example.com/philips-labs/slsa-provenance-action/actions/runs/1332651620"`)
+
+	t.Setenv("GITHUB_EVENT_PATH", "")
+	_, err = executeCommand(cli.Files(),
+		"--artifact-path", path.Join(rootDir, "bin/slsa-provenance"),
+		"--output-path", provenanceFile,
+	)
+	assert.EqualError(err, "missing GitHub Actions environment variables: GITHUB_EVENT_PATH")
+
+	t.Setenv("GITHUB_EVENT_PATH", unknownFile)
+	_, err = executeCommand(cli.Files(),
+		"--artifact-path", path.Join(rootDir, "bin/slsa-provenance"),
+		"--output-path", provenanceFile,
+	)
+	assert.EqualError(err, fmt.Sprintf("failed to read github event: open %s: no such file or directory", unknownFile))
+
+	_, err = executeCommand(cli.Files(),
+		"--artifact-path", path.Join(rootDir, "bin/slsa-provenance"),
+		"--output-path", provenanceFile,
+		"--github-context", base64.StdEncoding.EncodeToString([]byte(githubContext)),
+	)
+	assert.NoError(err)
+}
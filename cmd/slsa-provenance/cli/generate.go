@@ -1,7 +1,19 @@
 package cli
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
 	"github.com/spf13/cobra"
+
+	"github.com/philips-labs/slsa-provenance-action/cmd/slsa-provenance/cli/options"
+	"github.com/philips-labs/slsa-provenance-action/pkg/github"
+	"github.com/philips-labs/slsa-provenance-action/pkg/gitlab"
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+	"github.com/philips-labs/slsa-provenance-action/pkg/signer"
 )
 
 // Generate creates an instance of *cobra.Command to generate provenance
@@ -12,6 +24,7 @@ func Generate() *cobra.Command {
 	}
 
 	cmd.AddCommand(
+		Checksums(),
 		Files(),
 		GitHubRelease(),
 		OCI(),
@@ -19,3 +32,92 @@ func Generate() *cobra.Command {
 
 	return cmd
 }
+
+type provenancePersister interface {
+	PersistProvenanceStatement(ctx context.Context, stmt *intoto.Statement, path string) error
+	PersistProvenanceEnvelope(ctx context.Context, env *intoto.Envelope, path string) error
+}
+
+// provenanceEnvironment generates and persists provenance for a CI provider
+type provenanceEnvironment interface {
+	provenancePersister
+	GenerateProvenanceStatement(ctx context.Context, subjecter intoto.Subjecter, materials ...intoto.Item) (*intoto.Statement, error)
+}
+
+// newProvenanceEnvironment creates the environment of the CI provider selected or detected by the options
+func newProvenanceEnvironment(o *options.GenerateOptions) (provenanceEnvironment, error) {
+	ci, err := o.GetCI()
+	if err != nil {
+		return nil, err
+	}
+
+	if ci == options.CIGitLab {
+		gl, err := o.GetGitLabContext()
+		if err != nil {
+			return nil, err
+		}
+		predicateVersion, err := o.GetPredicateVersion()
+		if err != nil {
+			return nil, err
+		}
+		return &gitlab.Environment{Context: gl, PredicateVersion: predicateVersion}, nil
+	}
+
+	gh, err := o.GetGitHubContext()
+	if err != nil {
+		return nil, err
+	}
+	runner, err := o.GetRunnerContext()
+	if err != nil {
+		return nil, err
+	}
+	predicateVersion, err := o.GetPredicateVersion()
+	if err != nil {
+		return nil, err
+	}
+	return &github.Environment{Context: gh, Runner: runner, PredicateVersion: predicateVersion}, nil
+}
+
+// persistProvenance writes the statement in the requested output format.
+// When a signer is given the statement is always wrapped in a signed DSSE envelope.
+func persistProvenance(ctx context.Context, p provenancePersister, stmt *intoto.Statement, outputFormat, outputPath string, s signer.Signer) error {
+	if outputFormat != options.OutputFormatDSSE && s == nil {
+		return p.PersistProvenanceStatement(ctx, stmt, outputPath)
+	}
+
+	env, err := intoto.NewEnvelope(stmt)
+	if err != nil {
+		return fmt.Errorf("failed to create provenance envelope: %w", err)
+	}
+
+	if s != nil {
+		if err := signer.SignEnvelope(env, s); err != nil {
+			return fmt.Errorf("failed to sign provenance envelope: %w", err)
+		}
+	}
+
+	return p.PersistProvenanceEnvelope(ctx, env, outputPath)
+}
+
+// printSubjects lists the hashed subjects, used to report the included artifacts in verbose mode
+func printSubjects(out io.Writer, subjects []intoto.Subject) {
+	fmt.Fprintf(out, "Hashed %d artifact(s):\n", len(subjects))
+	for _, s := range subjects {
+		fmt.Fprintf(out, "  %s %s\n", s.Name, formatDigests(s.Digest))
+	}
+}
+
+// formatDigests formats the digests as alg:hex sorted by algorithm
+func formatDigests(digestSet intoto.DigestSet) string {
+	algs := make([]string, 0, len(digestSet))
+	for alg := range digestSet {
+		algs = append(algs, alg)
+	}
+	sort.Strings(algs)
+
+	digests := make([]string, len(algs))
+	for i, alg := range algs {
+		digests[i] = fmt.Sprintf("%s:%s", alg, digestSet[alg])
+	}
+	return strings.Join(digests, " ")
+}
@@ -13,7 +13,7 @@ func TestGenerate(t *testing.T) {
 
 	cmd := cli.Generate()
 
-	assert.Len(cmd.Commands(), 3)
+	assert.Len(cmd.Commands(), 4)
 	output, err := executeCommand(cmd)
 
 	assert.NoError(err)
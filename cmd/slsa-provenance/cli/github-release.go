@@ -29,6 +29,22 @@ func GitHubRelease() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			outputFormat, err := o.GetOutputFormat()
+			if err != nil {
+				return err
+			}
+			signer, err := o.GetSigner()
+			if err != nil {
+				return err
+			}
+
+			ci, err := o.GetCI()
+			if err != nil {
+				return err
+			}
+			if ci != options.CIGitHub {
+				return fmt.Errorf("github-release provenance is only supported on ci %s, got %s", options.CIGitHub, ci)
+			}
 
 			gh, err := o.GetGitHubContext()
 			if err != nil {
@@ -49,6 +65,46 @@ func GitHubRelease() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			releaseID, err := o.GetReleaseID()
+			if err != nil {
+				return err
+			}
+
+			predicateVersion, err := o.GetPredicateVersion()
+			if err != nil {
+				return err
+			}
+
+			downloadOpts, err := o.GetDownloadOptions()
+			if err != nil {
+				return err
+			}
+
+			subjecterOpts, err := o.GetFilePathSubjecterOptions()
+			if err != nil {
+				return err
+			}
+
+			digestAlgorithms, err := o.GetDigestAlgorithms()
+			if err != nil {
+				return err
+			}
+			subjecterOpts = append(subjecterOpts, intoto.WithDigestAlgorithms(digestAlgorithms...))
+
+			hashWhileDownloading, err := o.HashWhileDownloading()
+			if err != nil {
+				return err
+			}
+
+			onConflict, err := o.GetConflictPolicy()
+			if err != nil {
+				return err
+			}
+
+			assetFilter, err := o.GetAssetFilter()
+			if err != nil {
+				return err
+			}
 
 			ghToken := os.Getenv("GITHUB_TOKEN")
 			if ghToken == "" {
@@ -57,20 +113,41 @@ func GitHubRelease() *cobra.Command {
 			tc := github.NewOAuth2Client(cmd.Context(), func() string { return ghToken })
 			tc.Transport = transport.TeeRoundTripper{
 				RoundTripper: tc.Transport,
-				Writer:       cmd.OutOrStdout(),
+				Writer:       transport.NewSyncWriter(cmd.OutOrStdout()),
+			}
+			apiURL := o.GetGitHubAPIURL(gh)
+			if gh.ServerURL == "" && apiURL != "" {
+				gh.ServerURL = github.ServerURLFromAPIURL(apiURL)
 			}
-			rc := github.NewReleaseClient(tc)
-			env := github.NewReleaseEnvironment(*gh, *runner, tagName, rc, artifactPath)
+			rc, err := github.NewEnterpriseReleaseClient(tc, apiURL)
+			if err != nil {
+				return err
+			}
+			rc.WithDownloadOptions(downloadOpts).WithConflictPolicy(onConflict).WithAssetFilter(assetFilter)
+			env := github.NewReleaseEnvironment(*gh, *runner, tagName, rc, artifactPath).WithReleaseID(releaseID)
+			env.PredicateVersion = predicateVersion
 
-			subjecter := intoto.NewFilePathSubjecter(artifactPath)
+			var subjecter intoto.Subjecter = intoto.NewFilePathSubjecter(artifactPath, subjecterOpts...)
+			if hashWhileDownloading {
+				subjecter = env.NewReleaseAssetSubjecter(cmd.Context(), digestAlgorithms...)
+			}
 			stmt, err := env.GenerateProvenanceStatement(cmd.Context(), subjecter, materials...)
 			if err != nil {
 				return fmt.Errorf("failed to generate provenance: %w", err)
 			}
+			if skipped := env.SkippedAssets(); len(skipped) > 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "Skipped %d release asset(s):\n", len(skipped))
+				for _, name := range skipped {
+					fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", name)
+				}
+			}
+			if ro.Verbose {
+				printSubjects(cmd.OutOrStdout(), stmt.Subject)
+			}
 
 			fmt.Fprintf(cmd.OutOrStdout(), "Saving provenance to %s\n", outputPath)
 
-			return env.PersistProvenanceStatement(cmd.Context(), stmt, outputPath)
+			return persistProvenance(cmd.Context(), env, stmt, outputFormat, outputPath, signer)
 		},
 	}
 
@@ -3,10 +3,17 @@ package cli_test
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"runtime"
+	"sync"
 	"testing"
+	"time"
 
 	gh "github.com/google/go-github/v41/github"
 	"github.com/stretchr/testify/assert"
@@ -74,6 +81,80 @@ func TestProvenenaceGitHubRelease(t *testing.T) {
 	}
 }
 
+func TestGitHubReleaseNoStoreAssets(t *testing.T) {
+	assert := assert.New(t)
+	withoutCI(t)
+
+	base64GitHubContext := base64.StdEncoding.EncodeToString([]byte(githubContext))
+	base64RunnerContext := base64.StdEncoding.EncodeToString([]byte(runnerContext))
+
+	_, err := executeCommand(cli.GitHubRelease(),
+		"--no-store-assets",
+		"--artifact-path",
+		"release-assets",
+		"--tag-name",
+		"v0.0.0-generate-test",
+	)
+	assert.EqualError(err, "--artifact-path can't be combined with --no-store-assets")
+
+	_, err = executeCommand(cli.GitHubRelease(),
+		"--no-store-assets",
+		"--archive-members",
+		"--github-context",
+		base64GitHubContext,
+		"--runner-context",
+		base64RunnerContext,
+		"--tag-name",
+		"v0.0.0-generate-test",
+	)
+	assert.EqualError(err, "--no-store-assets can't be combined with --archive-members")
+
+	_, err = executeCommand(cli.GitHubRelease(),
+		"--no-store-assets",
+		"--include",
+		"*.txt",
+		"--github-context",
+		base64GitHubContext,
+		"--runner-context",
+		base64RunnerContext,
+		"--tag-name",
+		"v0.0.0-generate-test",
+	)
+	assert.EqualError(err, "--no-store-assets can't be combined with --include")
+}
+
+func TestGitHubReleaseReleaseID(t *testing.T) {
+	assert := assert.New(t)
+	withoutCI(t)
+
+	base64GitHubContext := base64.StdEncoding.EncodeToString([]byte(githubContext))
+	base64RunnerContext := base64.StdEncoding.EncodeToString([]byte(runnerContext))
+
+	_, err := executeCommand(cli.GitHubRelease(),
+		"--artifact-path",
+		t.TempDir(),
+		"--github-context",
+		base64GitHubContext,
+		"--runner-context",
+		base64RunnerContext,
+	)
+	assert.EqualError(err, cli.RequiredFlagError("tag-name").Error())
+
+	_, err = executeCommand(cli.GitHubRelease(),
+		"--artifact-path",
+		t.TempDir(),
+		"--github-context",
+		base64GitHubContext,
+		"--runner-context",
+		base64RunnerContext,
+		"--tag-name",
+		"v0.0.0-generate-test",
+		"--release-id",
+		"51517953",
+	)
+	assert.EqualError(err, "--tag-name can't be combined with --release-id")
+}
+
 func createGitHubRelease(ctx context.Context, client *github.ReleaseClient, owner, repo, version string, assets ...string) (int64, error) {
 	rel, _, err := client.Repositories.CreateRelease(
 		ctx,
@@ -96,3 +177,233 @@ func createGitHubRelease(ctx context.Context, client *github.ReleaseClient, owne
 
 	return rel.GetID(), nil
 }
+
+func TestGitHubReleaseOnConflict(t *testing.T) {
+	assert := assert.New(t)
+	withoutCI(t)
+
+	base64GitHubContext := base64.StdEncoding.EncodeToString([]byte(githubContext))
+	base64RunnerContext := base64.StdEncoding.EncodeToString([]byte(runnerContext))
+
+	_, err := executeCommand(cli.GitHubRelease(),
+		"--artifact-path",
+		t.TempDir(),
+		"--github-context",
+		base64GitHubContext,
+		"--runner-context",
+		base64RunnerContext,
+		"--tag-name",
+		"v0.0.0-generate-test",
+		"--on-conflict",
+		"overwrite",
+	)
+	assert.EqualError(err, `unsupported on-conflict "overwrite", supported policies: fail, replace, skip, suffix`)
+}
+
+// fakeGitHubRelease serves release 1 (v0.0.0-generate-test) of philips-labs/slsa-provenance-action with the given
+// assets and accepts the uploads to the release. It reports the maximum number of assets downloaded in parallel.
+func fakeGitHubRelease(t *testing.T, assets map[string]string) (apiURL string, maxInFlight func() int) {
+	var (
+		mu       sync.Mutex
+		inFlight int
+		max      int
+	)
+
+	mux := http.NewServeMux()
+	var list []*gh.ReleaseAsset
+	id := int64(10)
+	for name, content := range assets {
+		name, content := name, content
+		list = append(list, &gh.ReleaseAsset{ID: gh.Int64(id), Name: gh.String(name), Size: gh.Int(len(content))})
+		mux.HandleFunc(fmt.Sprintf("/api/v3/repos/philips-labs/slsa-provenance-action/releases/assets/%d", id), func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			inFlight++
+			if inFlight > max {
+				max = inFlight
+			}
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+			}()
+
+			time.Sleep(20 * time.Millisecond)
+			_, _ = io.WriteString(w, content)
+		})
+		id++
+	}
+	release := &gh.RepositoryRelease{ID: gh.Int64(1), TagName: gh.String("v0.0.0-generate-test")}
+	mux.HandleFunc("/api/v3/repos/philips-labs/slsa-provenance-action/releases", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]*gh.RepositoryRelease{release})
+	})
+	mux.HandleFunc("/api/v3/repos/philips-labs/slsa-provenance-action/releases/tags/v0.0.0-generate-test", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(release)
+	})
+	mux.HandleFunc("/api/v3/repos/philips-labs/slsa-provenance-action/releases/1/assets", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(list)
+	})
+	mux.HandleFunc("/api/uploads/repos/philips-labs/slsa-provenance-action/releases/1/assets", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(&gh.ReleaseAsset{ID: gh.Int64(id), Name: gh.String(r.URL.Query().Get("name"))})
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv.URL + "/api/v3", func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return max
+	}
+}
+
+func TestGitHubReleaseArtifactOptions(t *testing.T) {
+	assert := assert.New(t)
+	withoutCI(t)
+	t.Setenv("GITHUB_TOKEN", "superSecret")
+
+	base64GitHubContext := base64.StdEncoding.EncodeToString([]byte(githubContext))
+	base64RunnerContext := base64.StdEncoding.EncodeToString([]byte(runnerContext))
+	assets := map[string]string{
+		"checksums.txt":        "checksums",
+		"salsa.tar.gz":         "salsa",
+		"salsa_linux_amd64":    "linux",
+		"salsa_darwin_arm64":   "darwin",
+		"salsa_windows_amd64":  "windows",
+		"salsa_freebsd_amd64":  "freebsd",
+		"release-notes.txt":    "notes",
+		"salsa.tar.gz.sbom.js": "sbom",
+	}
+
+	apiURL, maxInFlight := fakeGitHubRelease(t, assets)
+	artifactPath := path.Join(t.TempDir(), "assets")
+	provenanceFile := path.Join(t.TempDir(), "provenance.json")
+	_, err := executeCommand(cli.GitHubRelease(),
+		"--artifact-path",
+		artifactPath,
+		"--github-context",
+		base64GitHubContext,
+		"--runner-context",
+		base64RunnerContext,
+		"--github-api-url",
+		apiURL,
+		"--tag-name",
+		"v0.0.0-generate-test",
+		"--output-path",
+		provenanceFile,
+		"--concurrency",
+		"1",
+	)
+	assert.NoError(err)
+	assert.Equal(1, maxInFlight())
+	assert.Len(provenanceSubjects(t, provenanceFile), len(assets))
+
+	apiURL, maxInFlight = fakeGitHubRelease(t, assets)
+	artifactPath = path.Join(t.TempDir(), "assets")
+	_, err = executeCommand(cli.GitHubRelease(),
+		"--artifact-path",
+		artifactPath,
+		"--github-context",
+		base64GitHubContext,
+		"--runner-context",
+		base64RunnerContext,
+		"--github-api-url",
+		apiURL,
+		"--tag-name",
+		"v0.0.0-generate-test",
+		"--output-path",
+		provenanceFile,
+		"--concurrency",
+		"3",
+		"--include",
+		"*.txt",
+	)
+	assert.NoError(err)
+	assert.Equal(3, maxInFlight())
+	assert.ElementsMatch([]string{"checksums.txt", "release-notes.txt"}, provenanceSubjects(t, provenanceFile))
+	downloaded, err := os.ReadDir(artifactPath)
+	assert.NoError(err)
+	assert.Len(downloaded, len(assets))
+
+	_, err = executeCommand(cli.GitHubRelease(),
+		"--artifact-path",
+		t.TempDir(),
+		"--github-context",
+		base64GitHubContext,
+		"--runner-context",
+		base64RunnerContext,
+		"--tag-name",
+		"v0.0.0-generate-test",
+		"--concurrency",
+		"-1",
+	)
+	assert.EqualError(err, "invalid concurrency -1, must be 0 (default) or greater")
+}
+
+func TestGitHubReleaseAssetFilter(t *testing.T) {
+	assert := assert.New(t)
+	withoutCI(t)
+	t.Setenv("GITHUB_TOKEN", "superSecret")
+
+	base64GitHubContext := base64.StdEncoding.EncodeToString([]byte(githubContext))
+	base64RunnerContext := base64.StdEncoding.EncodeToString([]byte(runnerContext))
+
+	apiURL, _ := fakeGitHubRelease(t, map[string]string{
+		"salsa.tar.gz":     "salsa",
+		"salsa.tar.gz.sig": "signature",
+		"salsa.pem":        "certificate",
+	})
+	output, err := executeCommand(cli.GitHubRelease(),
+		"--no-store-assets",
+		"--github-context",
+		base64GitHubContext,
+		"--runner-context",
+		base64RunnerContext,
+		"--github-api-url",
+		apiURL,
+		"--release-id",
+		"1",
+		"--output-path",
+		path.Join(t.TempDir(), "provenance.json"),
+		"--asset-exclude",
+		"*.sig",
+		"--asset-exclude",
+		"*.pem",
+	)
+	assert.NoError(err)
+	assert.Contains(output, "Skipped 2 release asset(s):\n  salsa.pem\n  salsa.tar.gz.sig\n")
+
+	_, err = executeCommand(cli.GitHubRelease(),
+		"--no-store-assets",
+		"--github-context",
+		base64GitHubContext,
+		"--runner-context",
+		base64RunnerContext,
+		"--tag-name",
+		"v0.0.0-generate-test",
+		"--asset-include",
+		"[",
+	)
+	assert.EqualError(err, `invalid glob pattern "["`)
+}
+
+func provenanceSubjects(t *testing.T, provenanceFile string) []string {
+	content, err := os.ReadFile(provenanceFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stmt struct {
+		Subject []struct {
+			Name string `json:"name"`
+		} `json:"subject"`
+	}
+	if err := json.Unmarshal(content, &stmt); err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, len(stmt.Subject))
+	for i, s := range stmt.Subject {
+		names[i] = s.Name
+	}
+	return names
+}
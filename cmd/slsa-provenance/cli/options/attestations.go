@@ -0,0 +1,60 @@
+package options
+
+import (
+	"context"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/spf13/cobra"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/oci"
+)
+
+// AttestationsOptions Commandline flags used for the attestations commands.
+type AttestationsOptions struct {
+	AllowInsecure      bool
+	KubernetesKeychain bool
+}
+
+// AddFlags Registers the flags with the cobra.Command.
+func (o *AttestationsOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&o.AllowInsecure, "allow-insecure", false, "whether to allow insecure connections to registries. Don't use this for anything but testing")
+	cmd.Flags().BoolVar(&o.KubernetesKeychain, "k8s-keychain", false, "whether to use the kubernetes keychain instead of the default keychain (supports workload identity).")
+}
+
+// GetRegistryClientOpts sets some sane default options for crane to authenticate
+// private registries
+func (o *AttestationsOptions) GetRegistryClientOpts(ctx context.Context) []crane.Option {
+	return oci.WithDefaultClientOptions(ctx, o.KubernetesKeychain, o.AllowInsecure)
+}
+
+// AttestationsListOptions Commandline flags used for the attestations list command.
+type AttestationsListOptions struct {
+	AttestationsOptions
+	OutputJSON bool
+}
+
+// AddFlags Registers the flags with the cobra.Command.
+func (o *AttestationsListOptions) AddFlags(cmd *cobra.Command) {
+	o.AttestationsOptions.AddFlags(cmd)
+	cmd.Flags().BoolVar(&o.OutputJSON, "json", false, "Print the attestations as JSON.")
+}
+
+// AttestationsDownloadOptions Commandline flags used for the attestations download command.
+type AttestationsDownloadOptions struct {
+	AttestationsOptions
+	OutputPath string
+}
+
+// GetOutputPath The location to write the attestation envelopes to.
+func (o *AttestationsDownloadOptions) GetOutputPath() (string, error) {
+	if o.OutputPath == "" {
+		return "", RequiredFlagError("output-path")
+	}
+	return o.OutputPath, nil
+}
+
+// AddFlags Registers the flags with the cobra.Command.
+func (o *AttestationsDownloadOptions) AddFlags(cmd *cobra.Command) {
+	o.AttestationsOptions.AddFlags(cmd)
+	cmd.Flags().StringVar(&o.OutputPath, "output-path", "attestations.intoto.jsonl", "The path to which the attestation envelopes are written, one envelope per line.")
+}
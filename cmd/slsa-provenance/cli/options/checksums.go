@@ -0,0 +1,47 @@
+package options
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+)
+
+// ChecksumsOptions Commandline flags used for the generate checksums command.
+type ChecksumsOptions struct {
+	GenerateOptions
+	ChecksumsFiles    []string
+	ChecksumAlgorithm string
+	VerifyArtifacts   bool
+	ArtifactPath      string
+}
+
+// GetChecksumsFiles The checksums files (e.g. SHA256SUMS) to read the subjects from.
+func (o *ChecksumsOptions) GetChecksumsFiles() ([]string, error) {
+	if len(o.ChecksumsFiles) == 0 {
+		return nil, RequiredFlagError("checksums-file")
+	}
+	return o.ChecksumsFiles, nil
+}
+
+// GetChecksumsSubjecterOptions The options to parse and optionally verify the checksums.
+func (o *ChecksumsOptions) GetChecksumsSubjecterOptions() []intoto.ChecksumsSubjecterOption {
+	var opts []intoto.ChecksumsSubjecterOption
+	if o.ChecksumAlgorithm != "" {
+		opts = append(opts, intoto.WithChecksumAlgorithm(o.ChecksumAlgorithm))
+	}
+	if o.VerifyArtifacts {
+		opts = append(opts, intoto.WithVerifyArtifacts(o.ArtifactPath))
+	}
+	return opts
+}
+
+// AddFlags Registers the flags with the cobra.Command.
+func (o *ChecksumsOptions) AddFlags(cmd *cobra.Command) {
+	o.GenerateOptions.AddFlags(cmd)
+	// NOTE: the digest algorithms are taken from the checksums files.
+	_ = cmd.PersistentFlags().MarkHidden("digest-algorithms")
+	cmd.PersistentFlags().StringSliceVar(&o.ChecksumsFiles, "checksums-file", nil, "The checksums file(s) in GNU coreutils or BSD format to read the subjects from, e.g. SHA256SUMS.")
+	cmd.PersistentFlags().StringVar(&o.ChecksumAlgorithm, "checksum-algorithm", "", "The digest algorithm of GNU formatted checksums, derived from the checksum length when not set.")
+	cmd.PersistentFlags().BoolVar(&o.VerifyArtifacts, "verify-artifacts", false, "Re-hash the artifacts on disk and fail on any checksum mismatch.")
+	cmd.PersistentFlags().StringVar(&o.ArtifactPath, "artifact-path", "", "The directory of the artifacts to verify, defaults to the directory of the checksums file.")
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
 )
 
 // RequiredFlagError creates a required flag error for the given flag name
@@ -11,9 +13,46 @@ func RequiredFlagError(flagName string) error {
 	return fmt.Errorf("no value found for required flag: %s", flagName)
 }
 
+// ArtifactOptions Commandline flags to select and hash the artifacts included in provenance.
+type ArtifactOptions struct {
+	Include        []string
+	Exclude        []string
+	Concurrency    int
+	ArchiveMembers bool
+}
+
+// GetFilePathSubjecterOptions The include and exclude patterns to apply while walking the artifact path,
+// the number of artifacts to hash in parallel and whether to hash the members of archives.
+func (o *ArtifactOptions) GetFilePathSubjecterOptions() ([]intoto.FilePathSubjecterOption, error) {
+	if err := intoto.ValidatePatterns(append(o.Include, o.Exclude...)...); err != nil {
+		return nil, err
+	}
+	if o.Concurrency < 0 {
+		return nil, fmt.Errorf("invalid concurrency %d, must be 0 (number of CPUs) or greater", o.Concurrency)
+	}
+	opts := []intoto.FilePathSubjecterOption{
+		intoto.WithIncludePatterns(o.Include...),
+		intoto.WithExcludePatterns(o.Exclude...),
+		intoto.WithConcurrency(o.Concurrency),
+	}
+	if o.ArchiveMembers {
+		opts = append(opts, intoto.WithArchiveMembers(intoto.DefaultArchiveLimits))
+	}
+	return opts, nil
+}
+
+// AddFlags Registers the flags with the cobra.Command.
+func (o *ArtifactOptions) AddFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringArrayVar(&o.Include, "include", nil, "Only include artifacts matching the doublestar glob pattern, relative to the artifact-path (can be repeated).")
+	cmd.PersistentFlags().StringArrayVar(&o.Exclude, "exclude", nil, "Exclude artifacts matching the doublestar glob pattern, relative to the artifact-path (can be repeated).")
+	cmd.PersistentFlags().IntVar(&o.Concurrency, "concurrency", 0, "The number of artifacts to hash in parallel, defaults to the number of CPUs.")
+	cmd.PersistentFlags().BoolVar(&o.ArchiveMembers, "archive-members", false, "Also include the members of .tar, .tar.gz, .tgz, .tar.zst and .zip artifacts, named like release.tar.gz!/bin/app.")
+}
+
 // FilesOptions Commandline flags used for the generate files command.
 type FilesOptions struct {
 	GenerateOptions
+	ArtifactOptions
 	ArtifactPath string
 }
 
@@ -28,5 +67,6 @@ func (o *FilesOptions) GetArtifactPath() (string, error) {
 // AddFlags Registers the flags with the cobra.Command.
 func (o *FilesOptions) AddFlags(cmd *cobra.Command) {
 	o.GenerateOptions.AddFlags(cmd)
+	o.ArtifactOptions.AddFlags(cmd)
 	cmd.PersistentFlags().StringVar(&o.ArtifactPath, "artifact-path", "", "The file(s) or directory of artifacts to include in provenance.")
 }
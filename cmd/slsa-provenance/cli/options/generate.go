@@ -8,21 +8,72 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/philips-labs/slsa-provenance-action/pkg/digest"
 	"github.com/philips-labs/slsa-provenance-action/pkg/github"
+	"github.com/philips-labs/slsa-provenance-action/pkg/gitlab"
 	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+	"github.com/philips-labs/slsa-provenance-action/pkg/signer"
+)
+
+const (
+	// OutputFormatStatement writes the bare in-toto Statement
+	OutputFormatStatement = "statement"
+	// OutputFormatDSSE writes the in-toto Statement wrapped in a DSSE envelope
+	OutputFormatDSSE = "dsse"
+
+	// CIGitHub generates provenance from the GitHub Actions contexts
+	CIGitHub = "github"
+	// CIGitLab generates provenance from the GitLab CI predefined variables
+	CIGitLab = "gitlab"
 )
 
 // GenerateOptions Commandline flags used for the generate command.
 type GenerateOptions struct {
-	GitHubContext  string
-	RunnerContext  string
-	OutputPath     string
-	ExtraMaterials []string
+	CI               string
+	GitHubContext    string
+	RunnerContext    string
+	OutputPath       string
+	OutputFormat     string
+	ExtraMaterials   []string
+	PredicateVersion string
+	SigningKey       string
+	DigestAlgorithms []string
 }
 
-// GetGitHubContext The '${github}' context value, retrieved in a GitHub workflow.
+// GetCI The CI provider to generate provenance for. When not given it is detected, the github-context flag or
+// the GITHUB_ACTIONS environment variable select GitHub Actions, the GITLAB_CI environment variable selects GitLab CI.
+// GitHub Actions is the default.
+func (o *GenerateOptions) GetCI() (string, error) {
+	switch o.CI {
+	case CIGitHub, CIGitLab:
+		return o.CI, nil
+	case "":
+	default:
+		return "", fmt.Errorf("unsupported ci %q, supported ci: %s, %s", o.CI, CIGitHub, CIGitLab)
+	}
+
+	switch {
+	case o.GitHubContext != "", github.IsGitHubActions(os.Getenv):
+		return CIGitHub, nil
+	case gitlab.IsGitLabCI(os.Getenv):
+		return CIGitLab, nil
+	default:
+		return CIGitHub, nil
+	}
+}
+
+// GetGitLabContext The GitLab CI job information, retrieved from the predefined variables in a GitLab CI job.
+func (o *GenerateOptions) GetGitLabContext() (*gitlab.Context, error) {
+	return gitlab.ContextFromEnv(os.Getenv)
+}
+
+// GetGitHubContext The '${github}' context value, retrieved in a GitHub workflow. When not given in a GitHub
+// workflow, it is read from the GITHUB_* environment variables.
 func (o *GenerateOptions) GetGitHubContext() (*github.Context, error) {
 	if o.GitHubContext == "" {
+		if github.IsGitHubActions(os.Getenv) {
+			return github.ContextFromEnv(os.Getenv)
+		}
 		return nil, RequiredFlagError("github-context")
 	}
 	decodedContext, err := base64.StdEncoding.DecodeString(o.GitHubContext)
@@ -36,9 +87,13 @@ func (o *GenerateOptions) GetGitHubContext() (*github.Context, error) {
 	return &gh, nil
 }
 
-// GetRunnerContext The '${runner}' context value, retrieved in a GitHub workflow.
+// GetRunnerContext The '${runner}' context value, retrieved in a GitHub workflow. When not given in a GitHub
+// workflow, it is read from the RUNNER_* environment variables.
 func (o *GenerateOptions) GetRunnerContext() (*github.RunnerContext, error) {
 	if o.RunnerContext == "" {
+		if github.IsGitHubActions(os.Getenv) {
+			return github.RunnerContextFromEnv(os.Getenv), nil
+		}
 		return nil, RequiredFlagError("runner-context")
 	}
 	decodedContext, err := base64.StdEncoding.DecodeString(o.RunnerContext)
@@ -60,6 +115,28 @@ func (o *GenerateOptions) GetOutputPath() (string, error) {
 	return o.OutputPath, nil
 }
 
+// GetOutputFormat The format in which the provenance file is written.
+func (o *GenerateOptions) GetOutputFormat() (string, error) {
+	switch o.OutputFormat {
+	case "", OutputFormatStatement:
+		return OutputFormatStatement, nil
+	case OutputFormatDSSE:
+		return OutputFormatDSSE, nil
+	default:
+		return "", fmt.Errorf("unsupported output-format %q, supported formats: %s, %s", o.OutputFormat, OutputFormatStatement, OutputFormatDSSE)
+	}
+}
+
+// GetSigner The signer for the signing key used to sign the provenance envelope, nil when no signing key is given.
+//
+// The password of an encrypted signing key is read from the SLSA_SIGNING_KEY_PASSWORD environment variable.
+func (o *GenerateOptions) GetSigner() (signer.Signer, error) {
+	if o.SigningKey == "" {
+		return nil, nil
+	}
+	return signer.NewSignerFromFile(o.SigningKey, func() string { return os.Getenv(signer.PasswordEnvVar) })
+}
+
 // GetExtraMaterials Additional material files to be used when generating provenance.
 func (o *GenerateOptions) GetExtraMaterials() ([]intoto.Item, error) {
 	var materials []intoto.Item
@@ -81,10 +158,38 @@ func (o *GenerateOptions) GetExtraMaterials() ([]intoto.Item, error) {
 	return materials, nil
 }
 
+// GetPredicateVersion The SLSA provenance predicate version to generate.
+func (o *GenerateOptions) GetPredicateVersion() (string, error) {
+	switch o.PredicateVersion {
+	case "", intoto.PredicateVersionV02:
+		return intoto.PredicateVersionV02, nil
+	case intoto.PredicateVersionV1, "v1.0":
+		return intoto.PredicateVersionV1, nil
+	default:
+		return "", fmt.Errorf("unsupported predicate-version %q, supported versions: %s, %s", o.PredicateVersion, intoto.PredicateVersionV02, intoto.PredicateVersionV1)
+	}
+}
+
+// GetDigestAlgorithms The digest algorithms to compute for each subject.
+func (o *GenerateOptions) GetDigestAlgorithms() ([]string, error) {
+	if len(o.DigestAlgorithms) == 0 {
+		return digest.Default, nil
+	}
+	if err := digest.Validate(o.DigestAlgorithms...); err != nil {
+		return nil, err
+	}
+	return o.DigestAlgorithms, nil
+}
+
 // AddFlags Registers the flags with the cobra.Command.
 func (o *GenerateOptions) AddFlags(cmd *cobra.Command) {
-	cmd.PersistentFlags().StringVar(&o.GitHubContext, "github-context", "", "The '${github}' context value.")
-	cmd.PersistentFlags().StringVar(&o.RunnerContext, "runner-context", "", "The '${runner}' context value.")
+	cmd.PersistentFlags().StringVar(&o.CI, "ci", "", "The CI provider to generate provenance for (github, gitlab), detected from the environment when not given.")
+	cmd.PersistentFlags().StringVar(&o.GitHubContext, "github-context", "", "The '${github}' context value, read from the GITHUB_* environment variables when not given in a GitHub workflow.")
+	cmd.PersistentFlags().StringVar(&o.RunnerContext, "runner-context", "", "The '${runner}' context value, read from the RUNNER_* environment variables when not given in a GitHub workflow.")
 	cmd.PersistentFlags().StringVar(&o.OutputPath, "output-path", "provenance.json", "The path to which the generated provenance should be written.")
+	cmd.PersistentFlags().StringVar(&o.OutputFormat, "output-format", OutputFormatStatement, "The format of the generated provenance (statement, dsse).")
 	cmd.PersistentFlags().StringSliceVarP(&o.ExtraMaterials, "extra-materials", "m", nil, "The '${runner}' context value.")
+	cmd.PersistentFlags().StringVar(&o.PredicateVersion, "predicate-version", intoto.PredicateVersionV02, "The SLSA provenance predicate version to generate (v0.2, v1).")
+	cmd.PersistentFlags().StringVar(&o.SigningKey, "signing-key", "", "The PEM encoded private key (ed25519, ECDSA P-256 or RSA) used to sign the provenance envelope (implies --output-format dsse).")
+	cmd.PersistentFlags().StringSliceVar(&o.DigestAlgorithms, "digest-algorithms", digest.Default, "The digest algorithms to compute for each subject (sha256, sha384, sha512, sha1, blake2b-256, gitoid:blob:sha1).")
 }
@@ -1,36 +1,140 @@
 package options
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+
 	"github.com/spf13/cobra"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/github"
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
 )
 
 // GitHubReleaseOptions Commandline flags used for the generate command.
 type GitHubReleaseOptions struct {
 	GenerateOptions
-	ArtifactPath string
-	TagName      string
+	ArtifactOptions
+	ArtifactPath  string
+	TagName       string
+	ReleaseID     int64
+	GitHubAPIURL  string
+	NoStoreAssets bool
+	OnConflict    string
+	AssetInclude  []string
+	AssetExclude  []string
 }
 
-// GetArtifactPath The location to store the GitHub Release artifact
+// GetArtifactPath The location to store the GitHub Release artifact, empty when the assets are not stored
 func (o *GitHubReleaseOptions) GetArtifactPath() (string, error) {
+	if o.NoStoreAssets {
+		if o.ArtifactPath != "" {
+			return "", errors.New("--artifact-path can't be combined with --no-store-assets")
+		}
+		return "", nil
+	}
 	if o.ArtifactPath == "" {
 		return "", RequiredFlagError("artifact-path")
 	}
 	return o.ArtifactPath, nil
 }
 
-// GetTagName The name of the GitHub tag/release
+// GetTagName The name of the GitHub tag/release, empty when the release is given by its release-id
 func (o *GitHubReleaseOptions) GetTagName() (string, error) {
-	if o.TagName == "" {
+	if o.TagName != "" && o.ReleaseID != 0 {
+		return "", errors.New("--tag-name can't be combined with --release-id")
+	}
+	if o.TagName == "" && o.ReleaseID == 0 {
 		return "", RequiredFlagError("tag-name")
 	}
 	return o.TagName, nil
 }
 
+// GetReleaseID The ID of the GitHub release, 0 when the release is given by its tag-name
+func (o *GitHubReleaseOptions) GetReleaseID() (int64, error) {
+	if o.ReleaseID < 0 {
+		return 0, fmt.Errorf("invalid release-id %d", o.ReleaseID)
+	}
+	return o.ReleaseID, nil
+}
+
+// HashWhileDownloading Whether to hash the release assets while downloading them. The include, exclude and
+// archive-members flags select the artifacts while walking the artifact-path, these require the assets to be stored
+// and hashed afterwards.
+func (o *GitHubReleaseOptions) HashWhileDownloading() (bool, error) {
+	walkFlag := o.walkFlag()
+	if walkFlag != "" && o.NoStoreAssets {
+		return false, fmt.Errorf("--no-store-assets can't be combined with --%s", walkFlag)
+	}
+	return walkFlag == "", nil
+}
+
+// walkFlag the name of the first given flag that only applies when walking the stored assets
+func (o *GitHubReleaseOptions) walkFlag() string {
+	switch {
+	case o.ArchiveMembers:
+		return "archive-members"
+	case len(o.Include) > 0:
+		return "include"
+	case len(o.Exclude) > 0:
+		return "exclude"
+	default:
+		return ""
+	}
+}
+
+// GetDownloadOptions The options to download the release assets with, the concurrency defaults to the
+// github.DefaultDownloadOptions.
+func (o *GitHubReleaseOptions) GetDownloadOptions() (github.DownloadOptions, error) {
+	if o.Concurrency < 0 {
+		return github.DownloadOptions{}, fmt.Errorf("invalid concurrency %d, must be 0 (default) or greater", o.Concurrency)
+	}
+	return github.DownloadOptions{Concurrency: o.Concurrency, Retries: -1}, nil
+}
+
+// GetAssetFilter The asset-include and asset-exclude patterns selecting the release assets to download by name.
+func (o *GitHubReleaseOptions) GetAssetFilter() (github.AssetFilter, error) {
+	if err := intoto.ValidatePatterns(append(o.AssetInclude, o.AssetExclude...)...); err != nil {
+		return github.AssetFilter{}, err
+	}
+	return github.AssetFilter{Include: o.AssetInclude, Exclude: o.AssetExclude}, nil
+}
+
+// GetConflictPolicy How to upload the provenance when the release already has an asset with the same name.
+func (o *GitHubReleaseOptions) GetConflictPolicy() (github.ConflictPolicy, error) {
+	if o.OnConflict == "" {
+		return github.ConflictFail, nil
+	}
+	policies := make([]string, len(github.ConflictPolicies))
+	for i, p := range github.ConflictPolicies {
+		if string(p) == o.OnConflict {
+			return p, nil
+		}
+		policies[i] = string(p)
+	}
+	return "", fmt.Errorf("unsupported on-conflict %q, supported policies: %s", o.OnConflict, strings.Join(policies, ", "))
+}
+
+// GetGitHubAPIURL The GitHub API to manage the release with, defaults to the api_url of the GitHub context.
+func (o *GitHubReleaseOptions) GetGitHubAPIURL(gh *github.Context) string {
+	if o.GitHubAPIURL != "" {
+		return o.GitHubAPIURL
+	}
+	return gh.APIURL
+}
+
 // AddFlags Registers the flags with the cobra.Command.
 func (o *GitHubReleaseOptions) AddFlags(cmd *cobra.Command) {
 	o.GenerateOptions.AddFlags(cmd)
+	o.ArtifactOptions.AddFlags(cmd)
+	cmd.PersistentFlags().Lookup("concurrency").Usage = fmt.Sprintf("The number of release assets to download and hash in parallel, defaults to %d.", github.DefaultDownloadOptions.Concurrency)
 	cmd.PersistentFlags().StringVar(&o.ArtifactPath, "artifact-path", "", "The file(s) or directory of artifacts to include in provenance.")
 	cmd.PersistentFlags().StringVar(&o.TagName, "tag-name", "", `The github release to generate provenance on.
 	(if set the artifacts will be downloaded from the release and the provenance will be added as an additional release asset.)`)
+	cmd.PersistentFlags().Int64Var(&o.ReleaseID, "release-id", 0, "The ID of the github release to generate provenance on, as an alternative to --tag-name.")
+	cmd.PersistentFlags().StringVar(&o.GitHubAPIURL, "github-api-url", "", "The GitHub API URL, e.g. https://github.example.com/api/v3 for GitHub Enterprise Server (defaults to the api_url of the GitHub context).")
+	cmd.PersistentFlags().BoolVar(&o.NoStoreAssets, "no-store-assets", false, "Only hash the release assets while downloading them, without storing them at the artifact-path.")
+	cmd.PersistentFlags().StringArrayVar(&o.AssetInclude, "asset-include", nil, "Only download the release assets with a name matching the doublestar glob pattern (can be repeated).")
+	cmd.PersistentFlags().StringArrayVar(&o.AssetExclude, "asset-exclude", nil, "Skip downloading the release assets with a name matching the doublestar glob pattern (can be repeated).")
+	cmd.PersistentFlags().StringVar(&o.OnConflict, "on-conflict", string(github.ConflictFail), "How to upload the provenance when the release already has an asset with the same name (fail, replace, skip, suffix).")
 }
@@ -2,8 +2,12 @@ package options
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/google/go-containerregistry/pkg/crane"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/spf13/cobra"
 
 	"github.com/philips-labs/slsa-provenance-action/pkg/oci"
@@ -15,6 +19,11 @@ type OCIOptions struct {
 	Repository         string
 	Digest             string
 	Tags               []string
+	PlatformManifests  bool
+	Platforms          []string
+	Attach             string
+	OCILayout          string
+	Tarball            string
 	AllowInsecure      bool
 	KubernetesKeychain bool
 }
@@ -27,9 +36,10 @@ func (o *OCIOptions) GetRepository() (string, error) {
 	return o.Repository, nil
 }
 
-// GetDigest The digest to validate the tag digests against.
+// GetDigest The digest to validate the tag digests against, optional when the repository is pinned by digest or
+// when computing the digests from an OCI image layout or docker tarball.
 func (o *OCIOptions) GetDigest() (string, error) {
-	if o.Digest == "" {
+	if o.Digest == "" && !o.isPinned() && o.OCILayout == "" && o.Tarball == "" {
 		return "", RequiredFlagError("digest")
 	}
 	return o.Digest, nil
@@ -40,16 +50,85 @@ func (o *OCIOptions) GetTags() ([]string, error) {
 	return o.Tags, nil
 }
 
+// GetPlatforms The platforms of the image index manifests to add as provenance subjects.
+func (o *OCIOptions) GetPlatforms() ([]v1.Platform, error) {
+	platforms := make([]v1.Platform, len(o.Platforms))
+	for i, p := range o.Platforms {
+		platform, err := v1.ParsePlatform(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid platform %q: %w", p, err)
+		}
+		platforms[i] = *platform
+	}
+	return platforms, nil
+}
+
+// GetPlatformManifests Whether to add the image index platform manifests as provenance subjects.
+func (o *OCIOptions) GetPlatformManifests() bool {
+	return o.PlatformManifests || len(o.Platforms) > 0
+}
+
+// GetAttach The layout used to attach the provenance envelope to the image in the registry, empty when not attaching.
+func (o *OCIOptions) GetAttach() (string, error) {
+	switch o.Attach {
+	case "":
+		return "", nil
+	case oci.AttachLayoutTag, oci.AttachLayoutReferrers:
+		if o.Digest == "" && !o.isPinned() {
+			return "", RequiredFlagError("digest")
+		}
+		return o.Attach, nil
+	default:
+		return "", fmt.Errorf("unsupported attach layout %q, supported layouts: %s, %s", o.Attach, oci.AttachLayoutTag, oci.AttachLayoutReferrers)
+	}
+}
+
 // AddFlags Registers the flags with the cobra.Command.
 func (o *OCIOptions) AddFlags(cmd *cobra.Command) {
 	o.GenerateOptions.AddFlags(cmd)
-	cmd.PersistentFlags().StringVar(&o.Repository, "repository", "", "The repository of the oci artifact.")
-	cmd.PersistentFlags().StringVar(&o.Digest, "digest", "", "The digest for the oci artifact.")
-	cmd.PersistentFlags().StringSliceVar(&o.Tags, "tags", []string{"latest"}, "The given tags for this oci release.")
+	cmd.PersistentFlags().StringVar(&o.Repository, "repository", "", "The repository of the oci artifact, optionally pinned by digest (repository@sha256:...).")
+	cmd.PersistentFlags().StringVar(&o.Digest, "digest", "", "The digest for the oci artifact, the digests of the tags are verified against it.")
+	cmd.PersistentFlags().StringSliceVar(&o.Tags, "tags", nil, "The given tags for this oci release (defaults to latest, or the pinned digest when the repository is pinned by digest).")
+	cmd.PersistentFlags().BoolVar(&o.PlatformManifests, "platform-manifests", false, "Add a subject for each platform manifest of a multi-platform image index.")
+	cmd.PersistentFlags().StringSliceVar(&o.Platforms, "platform", nil, "Only add the platform manifests matching the given platforms, e.g. linux/amd64,linux/arm64/v8 (implies --platform-manifests).")
+	cmd.PersistentFlags().StringVar(&o.Attach, "attach", "", "Push the provenance envelope to the registry next to the image using the given layout (tag, referrers) (implies --output-format dsse).")
+	cmd.PersistentFlags().StringVar(&o.OCILayout, "oci-layout", "", "Compute the digests offline from the given OCI image layout directory instead of the registry.")
+	cmd.PersistentFlags().StringVar(&o.Tarball, "tarball", "", "Compute the digests offline from the given docker save tarball instead of the registry.")
 	cmd.Flags().BoolVar(&o.AllowInsecure, "allow-insecure", false, "whether to allow insecure connections to registries. Don't use this for anything but testing")
 	cmd.Flags().BoolVar(&o.KubernetesKeychain, "k8s-keychain", false, "whether to use the kubernetes keychain instead of the default keychain (supports workload identity).")
 }
 
+// GetSubjecter The subjecter computing the digests of the tags from the registry, an OCI image layout or a docker tarball.
+func (o *OCIOptions) GetSubjecter(ctx context.Context, repo, digest string, tags []string) (*oci.ContainerSubjecter, error) {
+	switch {
+	case o.OCILayout != "" && o.Tarball != "":
+		return nil, errors.New("only one of the flags oci-layout and tarball can be given")
+	case o.OCILayout != "":
+		return oci.NewLayoutSubjecter(o.OCILayout, repo, digest, tags), nil
+	case o.Tarball != "":
+		return oci.NewTarballSubjecter(o.Tarball, repo, digest, tags), nil
+	default:
+		return oci.NewContainerSubjecter(repo, digest, tags, o.GetRegistryClientOpts(ctx)...), nil
+	}
+}
+
+// GetImageReference The digest reference of the image, e.g. ghcr.io/owner/app@sha256:...
+func (o *OCIOptions) GetImageReference() (string, error) {
+	if o.isPinned() {
+		return o.Repository, nil
+	}
+	digest, err := o.GetDigest()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s@%s", o.Repository, digest), nil
+}
+
+// isPinned whether the repository is pinned by digest, e.g. ghcr.io/owner/app@sha256:...
+func (o *OCIOptions) isPinned() bool {
+	return strings.Contains(o.Repository, "@")
+}
+
 // GetRegistryClientOpts sets some sane default options for crane to authenticate
 // private registries
 func (o *OCIOptions) GetRegistryClientOpts(ctx context.Context) []crane.Option {
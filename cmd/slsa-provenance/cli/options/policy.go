@@ -0,0 +1,59 @@
+package options
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+	"github.com/philips-labs/slsa-provenance-action/pkg/policy"
+)
+
+// PolicyEvalOptions Commandline flags used for the policy eval command.
+type PolicyEvalOptions struct {
+	PolicyPath     string
+	ProvenancePath string
+	OutputJSON     bool
+}
+
+// GetPolicy The policy to evaluate the provenance against.
+func (o *PolicyEvalOptions) GetPolicy() (*policy.Policy, error) {
+	if o.PolicyPath == "" {
+		return nil, RequiredFlagError("policy")
+	}
+	return policy.Load(o.PolicyPath)
+}
+
+// GetStatement The provenance statement to evaluate, read from a statement or DSSE envelope file.
+func (o *PolicyEvalOptions) GetStatement() (*intoto.Statement, error) {
+	if o.ProvenancePath == "" {
+		return nil, RequiredFlagError("provenance")
+	}
+	content, err := os.ReadFile(o.ProvenancePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read provenance: %w", err)
+	}
+
+	var env intoto.Envelope
+	if err := json.Unmarshal(content, &env); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal provenance json: %w", err)
+	}
+	if env.PayloadType != "" {
+		return env.Statement()
+	}
+
+	var stmt intoto.Statement
+	if err := json.Unmarshal(content, &stmt); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal provenance json: %w", err)
+	}
+	return &stmt, nil
+}
+
+// AddFlags Registers the flags with the cobra.Command.
+func (o *PolicyEvalOptions) AddFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&o.PolicyPath, "policy", "", "The YAML or JSON policy file to evaluate the provenance against.")
+	cmd.PersistentFlags().StringVar(&o.ProvenancePath, "provenance", "", "The provenance statement or DSSE envelope to evaluate.")
+	cmd.PersistentFlags().BoolVar(&o.OutputJSON, "json", false, "print the policy violations as JSON")
+}
@@ -0,0 +1,107 @@
+package options
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/spf13/cobra"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+	"github.com/philips-labs/slsa-provenance-action/pkg/oci"
+	"github.com/philips-labs/slsa-provenance-action/pkg/signer"
+)
+
+// VerifyOptions Commandline flags used for the verify command.
+type VerifyOptions struct {
+	EnvelopePath       string
+	PublicKeys         []string
+	ArtifactPath       string
+	Image              string
+	BuilderID          string
+	SourceRepo         string
+	SourceRef          string
+	OutputJSON         bool
+	AllowInsecure      bool
+	KubernetesKeychain bool
+}
+
+// GetEnvelope The DSSE envelope holding the provenance to verify.
+func (o *VerifyOptions) GetEnvelope() (*intoto.Envelope, error) {
+	if o.EnvelopePath == "" {
+		return nil, RequiredFlagError("envelope")
+	}
+	content, err := os.ReadFile(o.EnvelopePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read envelope: %w", err)
+	}
+	var env intoto.Envelope
+	if err := json.Unmarshal(content, &env); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal envelope json: %w", err)
+	}
+	return &env, nil
+}
+
+// GetVerifiers The verifiers for the public keys used to verify the envelope signatures.
+func (o *VerifyOptions) GetVerifiers() ([]signer.Verifier, error) {
+	if len(o.PublicKeys) == 0 {
+		return nil, RequiredFlagError("public-key")
+	}
+	verifiers := make([]signer.Verifier, len(o.PublicKeys))
+	for i, k := range o.PublicKeys {
+		v, err := signer.NewVerifierFromFile(k)
+		if err != nil {
+			return nil, err
+		}
+		verifiers[i] = v
+	}
+	return verifiers, nil
+}
+
+// GetSubjecter The subjecter to recompute the subject digests from the artifact path or image reference.
+func (o *VerifyOptions) GetSubjecter(ctx context.Context) (intoto.Subjecter, error) {
+	switch {
+	case o.ArtifactPath != "" && o.Image != "":
+		return nil, errors.New("only one of the flags artifact-path and image can be given")
+	case o.ArtifactPath != "":
+		return intoto.NewFilePathSubjecter(o.ArtifactPath), nil
+	case o.Image != "":
+		ref, err := name.ParseReference(o.Image)
+		if err != nil {
+			return nil, err
+		}
+		tag, ok := ref.(name.Tag)
+		if !ok {
+			return oci.NewContainerSubjecter(o.Image, "", nil, o.GetRegistryClientOpts(ctx)...), nil
+		}
+		repo := strings.TrimSuffix(o.Image, ":"+tag.TagStr())
+		return oci.NewContainerSubjecter(repo, "", []string{tag.TagStr()}, o.GetRegistryClientOpts(ctx)...), nil
+	default:
+		return nil, RequiredFlagError("artifact-path or image")
+	}
+}
+
+// GetRegistryClientOpts sets some sane default options for crane to authenticate
+// private registries
+func (o *VerifyOptions) GetRegistryClientOpts(ctx context.Context) []crane.Option {
+	return oci.WithDefaultClientOptions(ctx, o.KubernetesKeychain, o.AllowInsecure)
+}
+
+// AddFlags Registers the flags with the cobra.Command.
+func (o *VerifyOptions) AddFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&o.EnvelopePath, "envelope", "", "The DSSE envelope holding the provenance to verify.")
+	cmd.PersistentFlags().StringSliceVar(&o.PublicKeys, "public-key", nil, "The PEM encoded public key(s) to verify the envelope signatures.")
+	cmd.PersistentFlags().StringVar(&o.ArtifactPath, "artifact-path", "", "The file(s) or directory of artifacts to verify the subjects against.")
+	cmd.PersistentFlags().StringVar(&o.Image, "image", "", "The image reference (repository:tag or repository@digest) to verify the subjects against.")
+	cmd.PersistentFlags().StringVar(&o.BuilderID, "builder-id", "", "The expected builder id.")
+	cmd.PersistentFlags().StringVar(&o.SourceRepo, "source-repo", "", "The expected source repository, e.g. github.com/philips-labs/slsa-provenance-action.")
+	cmd.PersistentFlags().StringVar(&o.SourceRef, "source-ref", "", "The expected source ref, e.g. refs/heads/main or main.")
+	cmd.PersistentFlags().BoolVar(&o.OutputJSON, "json", false, "print the verification result as JSON")
+	cmd.PersistentFlags().BoolVar(&o.AllowInsecure, "allow-insecure", false, "whether to allow insecure connections to registries. Don't use this for anything but testing")
+	cmd.PersistentFlags().BoolVar(&o.KubernetesKeychain, "k8s-keychain", false, "whether to use the kubernetes keychain instead of the default keychain (supports workload identity).")
+}
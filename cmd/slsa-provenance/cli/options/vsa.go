@@ -0,0 +1,67 @@
+package options
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+	"github.com/philips-labs/slsa-provenance-action/pkg/policy"
+)
+
+// DefaultVerifierID the verifier id recorded in the verification summary
+const DefaultVerifierID = "https://github.com/philips-labs/slsa-provenance-action"
+
+// VSAOptions Commandline flags used for the vsa command.
+type VSAOptions struct {
+	VerifyOptions
+	PolicyPath     string
+	VerifierID     string
+	VerifiedLevels []string
+	ResourceURI    string
+	OutputPath     string
+}
+
+// GetPolicy The optional policy to evaluate the provenance against, nil when not given.
+func (o *VSAOptions) GetPolicy() (*policy.Policy, error) {
+	if o.PolicyPath == "" {
+		return nil, nil
+	}
+	return policy.Load(o.PolicyPath)
+}
+
+// GetVerifiedLevels The SLSA levels the artifact is verified at.
+func (o *VSAOptions) GetVerifiedLevels() ([]string, error) {
+	if len(o.VerifiedLevels) == 0 {
+		return nil, RequiredFlagError("verified-levels")
+	}
+	return o.VerifiedLevels, nil
+}
+
+// GetResourceURI The uri of the verified resource, defaults to the image or artifact path.
+func (o *VSAOptions) GetResourceURI() string {
+	switch {
+	case o.ResourceURI != "":
+		return o.ResourceURI
+	case o.Image != "":
+		return o.Image
+	default:
+		return o.ArtifactPath
+	}
+}
+
+// GetOutputPath The location to write the verification summary.
+func (o *VSAOptions) GetOutputPath() (string, error) {
+	if o.OutputPath == "" {
+		return "", RequiredFlagError("output-path")
+	}
+	return o.OutputPath, nil
+}
+
+// AddFlags Registers the flags with the cobra.Command.
+func (o *VSAOptions) AddFlags(cmd *cobra.Command) {
+	o.VerifyOptions.AddFlags(cmd)
+	cmd.PersistentFlags().StringVar(&o.PolicyPath, "policy", "", "The YAML or JSON policy file to evaluate the provenance against.")
+	cmd.PersistentFlags().StringVar(&o.VerifierID, "verifier-id", DefaultVerifierID, "The id of the verifier recorded in the verification summary.")
+	cmd.PersistentFlags().StringSliceVar(&o.VerifiedLevels, "verified-levels", []string{intoto.SLSABuildLevel1}, "The SLSA levels the artifact is verified at.")
+	cmd.PersistentFlags().StringVar(&o.ResourceURI, "resource-uri", "", "The uri of the verified resource, defaults to the image or artifact path.")
+	cmd.PersistentFlags().StringVar(&o.OutputPath, "output-path", "vsa.json", "The location to write the verification summary.")
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/philips-labs/slsa-provenance-action/cmd/slsa-provenance/cli/options"
+	"github.com/philips-labs/slsa-provenance-action/pkg/policy"
+)
+
+// Policy creates an instance of *cobra.Command to work with provenance policies
+func Policy() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Evaluate provenance against policies using subcommands",
+	}
+
+	cmd.AddCommand(PolicyEval())
+
+	return cmd
+}
+
+// PolicyEval creates an instance of *cobra.Command to evaluate provenance against a policy
+func PolicyEval() *cobra.Command {
+	o := &options.PolicyEvalOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "eval",
+		Short: "Evaluate provenance against a policy and report all violated rules",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := o.GetPolicy()
+			if err != nil {
+				return err
+			}
+
+			stmt, err := o.GetStatement()
+			if err != nil {
+				return err
+			}
+
+			result := p.Evaluate(stmt)
+			if err := printPolicyResult(cmd.OutOrStdout(), result, o.OutputJSON); err != nil {
+				return err
+			}
+
+			return result.Err()
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
+
+func printPolicyResult(out io.Writer, result *policy.Result, outputJSON bool) error {
+	if outputJSON {
+		j, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("unable to generate JSON from policy result: %w", err)
+		}
+		fmt.Fprintln(out, string(j))
+		return nil
+	}
+
+	if result.Allowed() {
+		fmt.Fprintln(out, "Provenance complies with the policy")
+		return nil
+	}
+	fmt.Fprintf(out, "Provenance violates %d policy rule(s):\n", len(result.Violations))
+	for _, v := range result.Violations {
+		fmt.Fprintf(out, "  - %s: %s\n", v.Rule, v.Message)
+	}
+	return nil
+}
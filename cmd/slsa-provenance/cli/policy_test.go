@@ -0,0 +1,100 @@
+package cli_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/philips-labs/slsa-provenance-action/cmd/slsa-provenance/cli"
+)
+
+func TestPolicyEvalCliOptions(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	rootDir := path.Join(path.Dir(filename), "../../..")
+	provenanceFile := path.Join(rootDir, "bin/unittest-policy-provenance.json")
+	envelopeFile := path.Join(rootDir, "bin/unittest-policy-envelope.json")
+
+	for _, args := range [][]string{
+		{"--output-path", provenanceFile, "--predicate-version", "v1"},
+		{"--output-path", envelopeFile, "--output-format", "dsse", "--predicate-version", "v1"},
+	} {
+		_, err := executeCommand(cli.Files(), append([]string{
+			"--artifact-path", path.Join(rootDir, "bin/slsa-provenance"),
+			"--github-context", base64.StdEncoding.EncodeToString([]byte(githubContext)),
+			"--runner-context", base64.StdEncoding.EncodeToString([]byte(runnerContext)),
+		}, args...)...)
+		if !assert.NoError(t, err) {
+			return
+		}
+	}
+	defer func() {
+		_ = os.Remove(provenanceFile)
+		_ = os.Remove(envelopeFile)
+	}()
+
+	testCases := []struct {
+		name      string
+		err       error
+		contains  []string
+		arguments []string
+	}{
+		{
+			name:      "without commandline flags",
+			err:       cli.RequiredFlagError("policy"),
+			arguments: make([]string, 0),
+		},
+		{
+			name:      "without --provenance",
+			err:       cli.RequiredFlagError("provenance"),
+			arguments: []string{"--policy", path.Join(rootDir, "test-data/policy/policy-valid.yaml")},
+		},
+		{
+			name: "compliant provenance",
+			arguments: []string{
+				"--policy", path.Join(rootDir, "test-data/policy/policy-valid-v1.yaml"),
+				"--provenance", provenanceFile,
+			},
+			contains: []string{"Provenance complies with the policy"},
+		},
+		{
+			name: "compliant provenance envelope",
+			arguments: []string{
+				"--policy", path.Join(rootDir, "test-data/policy/policy-valid-v1.yaml"),
+				"--provenance", envelopeFile,
+				"--json",
+			},
+			contains: []string{`"violations": []`},
+		},
+		{
+			name: "violating provenance",
+			err:  fmt.Errorf(`provenance violates policy: builder-id: builder id "https://github.com/philips-labs/slsa-provenance-action/Attestations/SelfHostedActions@v1" is not allowed; materials: required material git+https://github.com/philips-labs/other-repo is missing; source-repo: source repository "https://github.com/philips-labs/slsa-provenance-action" is not allowed; source-branch: source ref "refs/heads/temp/dump-context" is not an allowed branch; completeness: completeness is not recorded in SLSA v1.0 provenance`),
+			arguments: []string{
+				"--policy", path.Join(rootDir, "test-data/policy/policy-strict.json"),
+				"--provenance", provenanceFile,
+			},
+			contains: []string{"Provenance violates 5 policy rule(s):", "  - builder-id:"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			assert := assert.New(tt)
+
+			output, err := executeCommand(cli.PolicyEval(), tc.arguments...)
+
+			if tc.err != nil {
+				assert.EqualError(err, tc.err.Error())
+			} else {
+				assert.NoError(err)
+			}
+			for _, c := range tc.contains {
+				assert.Contains(output, c)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/philips-labs/slsa-provenance-action/cmd/slsa-provenance/cli/options"
+	"github.com/philips-labs/slsa-provenance-action/pkg/verify"
+)
+
+// Verify creates an instance of *cobra.Command to verify signed provenance
+func Verify() *cobra.Command {
+	o := &options.VerifyOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify a signed provenance envelope against artifacts and expectations",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			env, err := o.GetEnvelope()
+			if err != nil {
+				return err
+			}
+
+			verifiers, err := o.GetVerifiers()
+			if err != nil {
+				return err
+			}
+
+			subjecter, err := o.GetSubjecter(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			result, err := verify.Envelope(env, verify.Options{
+				Verifiers:  verifiers,
+				Subjecter:  subjecter,
+				BuilderID:  o.BuilderID,
+				SourceRepo: o.SourceRepo,
+				SourceRef:  o.SourceRef,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to verify provenance: %w", err)
+			}
+
+			if err := printVerifyResult(cmd.OutOrStdout(), result, o.OutputJSON); err != nil {
+				return err
+			}
+
+			return result.Err()
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
+
+func printVerifyResult(out io.Writer, result *verify.Result, outputJSON bool) error {
+	if outputJSON {
+		j, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("unable to generate JSON from verification result: %w", err)
+		}
+		fmt.Fprintln(out, string(j))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, c := range result.Checks {
+		status := "PASS"
+		if !c.Passed {
+			status = "FAIL"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", status, c.Check, c.Reason)
+	}
+	return w.Flush()
+}
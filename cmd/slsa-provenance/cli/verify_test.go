@@ -0,0 +1,115 @@
+package cli_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/philips-labs/slsa-provenance-action/cmd/slsa-provenance/cli"
+	"github.com/philips-labs/slsa-provenance-action/pkg/verify"
+)
+
+func TestVerifyCliOptions(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	rootDir := path.Join(path.Dir(filename), "../../..")
+	artifactPath := path.Join(rootDir, "bin/slsa-provenance")
+	envelopeFile := path.Join(rootDir, "bin/unittest-verify-envelope.json")
+
+	_, err := executeCommand(cli.Files(),
+		"--artifact-path", artifactPath,
+		"--github-context", base64.StdEncoding.EncodeToString([]byte(githubContext)),
+		"--runner-context", base64.StdEncoding.EncodeToString([]byte(runnerContext)),
+		"--output-path", envelopeFile,
+		"--signing-key", path.Join(rootDir, "test-data/keys/ed25519.key"),
+		"--predicate-version", "v1",
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		_ = os.Remove(envelopeFile)
+	}()
+
+	testCases := []struct {
+		name      string
+		err       error
+		contains  []string
+		arguments []string
+	}{
+		{
+			name:      "without commandline flags",
+			err:       cli.RequiredFlagError("envelope"),
+			arguments: make([]string, 0),
+		},
+		{
+			name:      "without --public-key",
+			err:       cli.RequiredFlagError("public-key"),
+			arguments: []string{"--envelope", envelopeFile},
+		},
+		{
+			name: "without --artifact-path or --image",
+			err:  cli.RequiredFlagError("artifact-path or image"),
+			arguments: []string{
+				"--envelope", envelopeFile,
+				"--public-key", path.Join(rootDir, "test-data/keys/ed25519.pub"),
+			},
+		},
+		{
+			name: "valid envelope",
+			arguments: []string{
+				"--envelope", envelopeFile,
+				"--public-key", path.Join(rootDir, "test-data/keys/ed25519.pub"),
+				"--artifact-path", artifactPath,
+				"--builder-id", "https://github.com/philips-labs/slsa-provenance-action/Attestations/SelfHostedActions@v1",
+				"--source-repo", "github.com/philips-labs/slsa-provenance-action",
+				"--source-ref", "refs/heads/temp/dump-context",
+			},
+			contains: []string{"PASS  signature", "PASS  subjects", "PASS  builder-id", "PASS  source-repo", "PASS  source-ref"},
+		},
+		{
+			name: "valid envelope as json",
+			arguments: []string{
+				"--envelope", envelopeFile,
+				"--public-key", path.Join(rootDir, "test-data/keys/ed25519.pub"),
+				"--artifact-path", artifactPath,
+				"--json",
+			},
+			contains: []string{`"check": "signature"`, `"passed": true`},
+		},
+		{
+			name: "wrong public key and subjects",
+			err:  verify.ErrVerificationFailed,
+			arguments: []string{
+				"--envelope", envelopeFile,
+				"--public-key", path.Join(rootDir, "test-data/keys/ecdsa.pub"),
+				"--artifact-path", path.Join(rootDir, "test-data/keys/ecdsa.pub"),
+			},
+			contains: []string{"FAIL  signature", "FAIL  subjects"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			assert := assert.New(tt)
+
+			output, err := executeCommand(cli.Verify(), tc.arguments...)
+
+			switch {
+			case tc.err == nil:
+				assert.NoError(err)
+			case errors.Is(tc.err, verify.ErrVerificationFailed):
+				assert.ErrorIs(err, verify.ErrVerificationFailed)
+			default:
+				assert.EqualError(err, tc.err.Error())
+			}
+			for _, c := range tc.contains {
+				assert.Contains(output, c)
+			}
+		})
+	}
+}
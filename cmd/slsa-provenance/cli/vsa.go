@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/philips-labs/slsa-provenance-action/cmd/slsa-provenance/cli/options"
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+	"github.com/philips-labs/slsa-provenance-action/pkg/verify"
+)
+
+// VSA creates an instance of *cobra.Command to generate a Verification Summary Attestation
+func VSA() *cobra.Command {
+	o := &options.VSAOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "vsa",
+		Short: "Verify a signed provenance envelope and generate a Verification Summary Attestation",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			env, err := o.GetEnvelope()
+			if err != nil {
+				return err
+			}
+			verifiers, err := o.GetVerifiers()
+			if err != nil {
+				return err
+			}
+			subjecter, err := o.GetSubjecter(cmd.Context())
+			if err != nil {
+				return err
+			}
+			p, err := o.GetPolicy()
+			if err != nil {
+				return err
+			}
+			verifiedLevels, err := o.GetVerifiedLevels()
+			if err != nil {
+				return err
+			}
+			outputPath, err := o.GetOutputPath()
+			if err != nil {
+				return err
+			}
+
+			result, err := verify.Envelope(env, verify.Options{
+				Verifiers:  verifiers,
+				Subjecter:  subjecter,
+				BuilderID:  o.BuilderID,
+				SourceRepo: o.SourceRepo,
+				SourceRef:  o.SourceRef,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to verify provenance: %w", err)
+			}
+			if err := printVerifyResult(cmd.OutOrStdout(), result, o.OutputJSON); err != nil {
+				return err
+			}
+			if err := result.Err(); err != nil {
+				return err
+			}
+
+			envelope, err := fileDescriptor(o.EnvelopePath)
+			if err != nil {
+				return err
+			}
+			opts := []intoto.StatementOption{
+				intoto.WithSubject(result.Statement.Subject),
+				intoto.WithVerifier(o.VerifierID, map[string]string{cliName: GitVersion}),
+				intoto.WithVerifiedLevels(verifiedLevels...),
+				intoto.WithInputAttestations([]intoto.ResourceDescriptor{envelope}),
+				intoto.WithResourceURI(o.GetResourceURI()),
+			}
+
+			if p != nil {
+				policyResult := p.Evaluate(result.Statement)
+				if err := printPolicyResult(cmd.OutOrStdout(), policyResult, o.OutputJSON); err != nil {
+					return err
+				}
+				if err := policyResult.Err(); err != nil {
+					return err
+				}
+
+				policy, err := fileDescriptor(o.PolicyPath)
+				if err != nil {
+					return err
+				}
+				opts = append(opts, intoto.WithPolicy(policy.URI, policy.Digest))
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Saving verification summary to %s\n", outputPath)
+
+			return intoto.WriteProvenance(intoto.VerificationSummaryStatement(opts...), outputPath)
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
+
+// fileDescriptor describes the file at path by its path and sha256 digest
+func fileDescriptor(path string) (intoto.ResourceDescriptor, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return intoto.ResourceDescriptor{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	sum := sha256.Sum256(content)
+	return intoto.ResourceDescriptor{URI: path, Digest: intoto.DigestSet{"sha256": hex.EncodeToString(sum[:])}}, nil
+}
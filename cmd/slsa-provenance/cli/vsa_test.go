@@ -0,0 +1,140 @@
+package cli_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/philips-labs/slsa-provenance-action/cmd/slsa-provenance/cli"
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+	"github.com/philips-labs/slsa-provenance-action/pkg/policy"
+	"github.com/philips-labs/slsa-provenance-action/pkg/verify"
+)
+
+func TestVSACliOptions(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	rootDir := path.Join(path.Dir(filename), "../../..")
+	artifactPath := path.Join(rootDir, "bin/slsa-provenance")
+	envelopeFile := path.Join(rootDir, "bin/unittest-vsa-envelope.json")
+	vsaFile := path.Join(rootDir, "bin/unittest-vsa.json")
+
+	_, err := executeCommand(cli.Files(),
+		"--artifact-path", artifactPath,
+		"--github-context", base64.StdEncoding.EncodeToString([]byte(githubContext)),
+		"--runner-context", base64.StdEncoding.EncodeToString([]byte(runnerContext)),
+		"--output-path", envelopeFile,
+		"--signing-key", path.Join(rootDir, "test-data/keys/ed25519.key"),
+		"--predicate-version", "v1",
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		_ = os.Remove(envelopeFile)
+	}()
+
+	testCases := []struct {
+		name      string
+		err       error
+		levels    []string
+		policy    bool
+		arguments []string
+	}{
+		{
+			name:      "without commandline flags",
+			err:       cli.RequiredFlagError("envelope"),
+			arguments: make([]string, 0),
+		},
+		{
+			name: "verified envelope",
+			arguments: []string{
+				"--envelope", envelopeFile,
+				"--public-key", path.Join(rootDir, "test-data/keys/ed25519.pub"),
+				"--artifact-path", artifactPath,
+				"--output-path", vsaFile,
+			},
+			levels: []string{intoto.SLSABuildLevel1},
+		},
+		{
+			name: "verified envelope with policy",
+			arguments: []string{
+				"--envelope", envelopeFile,
+				"--public-key", path.Join(rootDir, "test-data/keys/ed25519.pub"),
+				"--artifact-path", artifactPath,
+				"--policy", path.Join(rootDir, "test-data/policy/policy-valid-v1.yaml"),
+				"--verified-levels", "SLSA_BUILD_LEVEL_1,SLSA_BUILD_LEVEL_2",
+				"--output-path", vsaFile,
+			},
+			levels: []string{intoto.SLSABuildLevel1, intoto.SLSABuildLevel2},
+			policy: true,
+		},
+		{
+			name: "failed verification",
+			err:  verify.ErrVerificationFailed,
+			arguments: []string{
+				"--envelope", envelopeFile,
+				"--public-key", path.Join(rootDir, "test-data/keys/ecdsa.pub"),
+				"--artifact-path", artifactPath,
+				"--output-path", vsaFile,
+			},
+		},
+		{
+			name: "policy violated",
+			err:  policy.ErrPolicyViolated,
+			arguments: []string{
+				"--envelope", envelopeFile,
+				"--public-key", path.Join(rootDir, "test-data/keys/ed25519.pub"),
+				"--artifact-path", artifactPath,
+				"--policy", path.Join(rootDir, "test-data/policy/policy-strict.json"),
+				"--output-path", vsaFile,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			assert := assert.New(tt)
+
+			output, err := executeCommand(cli.VSA(), tc.arguments...)
+			defer func() {
+				_ = os.Remove(vsaFile)
+			}()
+
+			if tc.err != nil {
+				if errors.Is(tc.err, verify.ErrVerificationFailed) || errors.Is(tc.err, policy.ErrPolicyViolated) {
+					assert.ErrorIs(err, tc.err)
+				} else {
+					assert.EqualError(err, tc.err.Error())
+				}
+				assert.NoFileExists(vsaFile)
+				return
+			}
+
+			assert.NoError(err)
+			assert.Contains(output, "Saving verification summary to")
+			content, err := os.ReadFile(vsaFile)
+			if !assert.NoError(err) {
+				return
+			}
+
+			var stmt intoto.Statement
+			assert.NoError(json.Unmarshal(content, &stmt))
+			assert.Equal(intoto.VerificationSummaryPredicateType, stmt.PredicateType)
+			if assert.NotNil(stmt.VerificationSummary) {
+				vsa := stmt.VerificationSummary
+				assert.Len(stmt.Subject, 1)
+				assert.Equal(intoto.VerificationResultPassed, vsa.VerificationResult)
+				assert.Equal(tc.levels, vsa.VerifiedLevels)
+				assert.Equal(artifactPath, vsa.ResourceURI)
+				assert.Len(vsa.InputAttestations, 1)
+				assert.Equal(tc.policy, vsa.Policy != nil)
+			}
+		})
+	}
+}
@@ -4,9 +4,13 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 )
 
 // TeeRoundTripper copies request bodies to stdout.
+//
+// Requests executed in parallel write to the Writer concurrently, wrap the Writer with NewSyncWriter when it isn't
+// safe for concurrent use.
 type TeeRoundTripper struct {
 	http.RoundTripper
 	Writer io.Writer
@@ -19,3 +23,21 @@ func (t TeeRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 
 	return t.RoundTripper.RoundTrip(req)
 }
+
+// SyncWriter serializes the writes to the underlying writer, so it can be shared by goroutines.
+type SyncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewSyncWriter creates a new instance of SyncWriter writing to w.
+func NewSyncWriter(w io.Writer) *SyncWriter {
+	return &SyncWriter{w: w}
+}
+
+// Write writes p to the underlying writer while holding the lock.
+func (s *SyncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -43,3 +44,40 @@ func TestTeeRoundTripper(t *testing.T) {
 	assert.NotEmpty(writer.String())
 	assert.Equal(fmt.Sprintf("GET: %s\n", ts.URL), writer.String())
 }
+
+func TestTeeRoundTripperParallel(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "Hello world")
+	}))
+	defer ts.Close()
+
+	var writer strings.Builder
+	client := http.Client{
+		Transport: transport.TeeRoundTripper{
+			RoundTripper: http.DefaultTransport,
+			Writer:       transport.NewSyncWriter(&writer),
+		},
+	}
+
+	const downloads = 16
+	var wg sync.WaitGroup
+	for i := 0; i < downloads; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := client.Get(fmt.Sprintf("%s/asset-%d", ts.URL, i))
+			if assert.NoError(err) {
+				_ = resp.Body.Close()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(writer.String(), "\n"), "\n")
+	assert.Len(lines, downloads)
+	for i := 0; i < downloads; i++ {
+		assert.Contains(lines, fmt.Sprintf("GET: %s/asset-%d", ts.URL, i))
+	}
+}
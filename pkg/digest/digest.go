@@ -0,0 +1,181 @@
+package digest
+
+import (
+	"bytes"
+	"crypto/sha1" // #nosec G505 -- sha1 is only computed when explicitly requested, e.g. for gitoid compatibility
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	// SHA256 the sha256 digest algorithm
+	SHA256 = "sha256"
+	// SHA384 the sha384 digest algorithm
+	SHA384 = "sha384"
+	// SHA512 the sha512 digest algorithm
+	SHA512 = "sha512"
+	// SHA1 the sha1 digest algorithm
+	SHA1 = "sha1"
+	// Blake2b256 the blake2b digest algorithm with a 256 bit output
+	Blake2b256 = "blake2b-256"
+	// GitoidBlobSHA1 the git object id of the content as a blob, see https://www.iana.org/assignments/uri-schemes/prov/gitoid
+	GitoidBlobSHA1 = "gitoid:blob:sha1"
+)
+
+// Supported the supported digest algorithms
+var Supported = []string{SHA256, SHA384, SHA512, SHA1, Blake2b256, GitoidBlobSHA1}
+
+// Default the digest algorithms used when none are given
+var Default = []string{SHA256}
+
+// Validate checks all algorithms are supported
+func Validate(algorithms ...string) error {
+	for _, alg := range algorithms {
+		if !isSupported(alg) {
+			return fmt.Errorf("unsupported digest algorithm %q, supported algorithms: %s", alg, strings.Join(Supported, ", "))
+		}
+	}
+	return nil
+}
+
+func isSupported(alg string) bool {
+	for _, s := range Supported {
+		if s == alg {
+			return true
+		}
+	}
+	return false
+}
+
+// New creates the hash for the given algorithm. The size of the content is required to compute the gitoid header.
+func New(alg string, size int64) (hash.Hash, error) {
+	switch alg {
+	case SHA256:
+		return sha256.New(), nil
+	case SHA384:
+		return sha512.New384(), nil
+	case SHA512:
+		return sha512.New(), nil
+	case SHA1:
+		return sha1.New(), nil // #nosec G401
+	case Blake2b256:
+		return blake2b.New256(nil)
+	case GitoidBlobSHA1:
+		h := sha1.New() // #nosec G401
+		fmt.Fprintf(h, "blob %d\x00", size)
+		return h, nil
+	default:
+		return nil, Validate(alg)
+	}
+}
+
+// Hasher computes the digests of all algorithms while the content is written to it, e.g. using an io.TeeReader
+type Hasher struct {
+	hashes  map[string]hash.Hash
+	writer  io.Writer
+	size    int64
+	written int64
+}
+
+// NewHasher creates a Hasher for content of the given size. The size is only used by the gitoid algorithms.
+func NewHasher(size int64, algorithms ...string) (*Hasher, error) {
+	if len(algorithms) == 0 {
+		algorithms = Default
+	}
+
+	hashes := make(map[string]hash.Hash, len(algorithms))
+	writers := make([]io.Writer, 0, len(algorithms))
+	for _, alg := range algorithms {
+		if _, ok := hashes[alg]; ok {
+			continue
+		}
+		h, err := New(alg, size)
+		if err != nil {
+			return nil, err
+		}
+		hashes[alg] = h
+		writers = append(writers, h)
+	}
+	return &Hasher{hashes: hashes, writer: io.MultiWriter(writers...), size: size}, nil
+}
+
+// Write adds the content to the hashes of all algorithms
+func (h *Hasher) Write(p []byte) (int, error) {
+	n, err := h.writer.Write(p)
+	h.written += int64(n)
+	return n, err
+}
+
+// Digests returns the hex encoded digest by algorithm of the content written so far
+func (h *Hasher) Digests() (map[string]string, error) {
+	if _, ok := h.hashes[GitoidBlobSHA1]; ok && h.written != h.size {
+		return nil, fmt.Errorf("content size changed while hashing, expected %d bytes, got %d", h.size, h.written)
+	}
+
+	digests := make(map[string]string, len(h.hashes))
+	for alg, hh := range h.hashes {
+		digests[alg] = hex.EncodeToString(hh.Sum(nil))
+	}
+	return digests, nil
+}
+
+// FromReader hashes the content with all algorithms in a single streaming pass.
+// The size of the content is only used by the gitoid algorithms.
+func FromReader(r io.Reader, size int64, algorithms ...string) (map[string]string, error) {
+	h, err := NewHasher(size, algorithms...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	return h.Digests()
+}
+
+// FromBytes hashes the content with all algorithms
+func FromBytes(b []byte, algorithms ...string) (map[string]string, error) {
+	return FromReader(bytes.NewReader(b), int64(len(b)), algorithms...)
+}
+
+// FromFile streams the file content through the hashes of all algorithms, without loading the file in memory
+func FromFile(path string, algorithms ...string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	digests, err := FromReader(file, info.Size(), algorithms...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return digests, nil
+}
+
+// SHA256Hex calculates the hex encoded sha256 checksum of the content
+func SHA256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// Parse splits a digest like sha256:abc into the algorithm and the hex encoded value
+func Parse(digest string) (alg, value string, err error) {
+	i := strings.LastIndex(digest, ":")
+	if i <= 0 || i == len(digest)-1 {
+		return "", "", fmt.Errorf("invalid digest %q, expected <algorithm>:<hex>", digest)
+	}
+	return digest[:i], digest[i+1:], nil
+}
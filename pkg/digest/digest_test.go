@@ -0,0 +1,112 @@
+package digest_test
+
+import (
+	"io"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/digest"
+)
+
+const content = "hello world\n"
+
+var expected = map[string]string{
+	digest.SHA256:         "a948904f2f0f479b8f8197694b30184b0d2ed1c1cd2a1ec0fb85d299a192a447",
+	digest.SHA384:         "6b3b69ff0a404f28d75e98a066d3fc64fffd9940870cc68bece28545b9a75086b343d7a1366838083e4b8f3ca6fd3c80",
+	digest.SHA512:         "db3974a97f2407b7cae1ae637c0030687a11913274d578492558e39c16c017de84eacdc8c62fe34ee4e12b4b1428817f09b6a2760c3f8a664ceae94d2434a593",
+	digest.SHA1:           "22596363b3de40b06f981fb85d82312e8c0ed511",
+	digest.Blake2b256:     "c71b05fd1d1c7bf7e928ff18e58db5193e9316416cc26ba9cc9094da80d7011e",
+	digest.GitoidBlobSHA1: "3b18e512dba79e4c8300dd08aeb37f8e728b8dad",
+}
+
+func TestFromBytes(t *testing.T) {
+	assert := assert.New(t)
+
+	digests, err := digest.FromBytes([]byte(content), digest.Supported...)
+	assert.NoError(err)
+	assert.Equal(expected, digests)
+
+	digests, err = digest.FromBytes([]byte(content))
+	assert.NoError(err)
+	assert.Equal(map[string]string{digest.SHA256: expected[digest.SHA256]}, digests)
+
+	digests, err = digest.FromBytes(nil, digest.GitoidBlobSHA1)
+	assert.NoError(err)
+	assert.Equal("e69de29bb2d1d6434b8b29ae775ad8c2e48c5391", digests[digest.GitoidBlobSHA1])
+
+	_, err = digest.FromBytes([]byte(content), "md5")
+	assert.EqualError(err, `unsupported digest algorithm "md5", supported algorithms: sha256, sha384, sha512, sha1, blake2b-256, gitoid:blob:sha1`)
+}
+
+func TestFromReaderSizeMismatch(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := digest.FromReader(strings.NewReader(content), 3, digest.GitoidBlobSHA1)
+	assert.EqualError(err, "content size changed while hashing, expected 3 bytes, got 12")
+}
+
+func TestHasher(t *testing.T) {
+	assert := assert.New(t)
+
+	h, err := digest.NewHasher(int64(len(content)), digest.SHA256, digest.GitoidBlobSHA1)
+	if !assert.NoError(err) {
+		return
+	}
+	_, err = io.Copy(h, iotest.HalfReader(strings.NewReader(content)))
+	assert.NoError(err)
+
+	digests, err := h.Digests()
+	assert.NoError(err)
+	assert.Equal(map[string]string{
+		digest.SHA256:         expected[digest.SHA256],
+		digest.GitoidBlobSHA1: expected[digest.GitoidBlobSHA1],
+	}, digests)
+
+	_, err = digest.NewHasher(0, "md5")
+	assert.Error(err)
+}
+
+func TestFromFile(t *testing.T) {
+	assert := assert.New(t)
+
+	file := path.Join(t.TempDir(), "hello.txt")
+	assert.NoError(os.WriteFile(file, []byte(content), 0644))
+
+	digests, err := digest.FromFile(file, digest.SHA512, digest.GitoidBlobSHA1)
+	assert.NoError(err)
+	assert.Equal(map[string]string{
+		digest.SHA512:         expected[digest.SHA512],
+		digest.GitoidBlobSHA1: expected[digest.GitoidBlobSHA1],
+	}, digests)
+
+	_, err = digest.FromFile(path.Join(t.TempDir(), "non-existing"))
+	assert.ErrorIs(err, os.ErrNotExist)
+}
+
+func TestParse(t *testing.T) {
+	assert := assert.New(t)
+
+	alg, value, err := digest.Parse("sha256:" + expected[digest.SHA256])
+	assert.NoError(err)
+	assert.Equal(digest.SHA256, alg)
+	assert.Equal(expected[digest.SHA256], value)
+
+	alg, value, err = digest.Parse("gitoid:blob:sha1:" + expected[digest.GitoidBlobSHA1])
+	assert.NoError(err)
+	assert.Equal(digest.GitoidBlobSHA1, alg)
+	assert.Equal(expected[digest.GitoidBlobSHA1], value)
+
+	for _, invalid := range []string{"", "sha256", "sha256:", ":abc"} {
+		_, _, err = digest.Parse(invalid)
+		assert.Error(err, invalid)
+	}
+}
+
+func TestSHA256Hex(t *testing.T) {
+	assert.Equal(t, expected[digest.SHA256], digest.SHA256Hex([]byte(content)))
+}
@@ -0,0 +1,235 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/v41/github"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/digest"
+)
+
+// DownloadOptions configures how DownloadReleaseAssets downloads the release assets
+type DownloadOptions struct {
+	// Concurrency the number of assets downloaded in parallel
+	Concurrency int
+	// Timeout the timeout of a single download attempt of an asset
+	Timeout time.Duration
+	// Retries the number of retries of an asset on network errors and 5xx or 429 responses
+	Retries int
+	// Backoff the delay before the first retry, doubled on each following retry
+	Backoff time.Duration
+}
+
+// DefaultDownloadOptions the options used to download release assets
+var DefaultDownloadOptions = DownloadOptions{
+	Concurrency: 4,
+	Timeout:     10 * time.Minute,
+	Retries:     3,
+	Backoff:     time.Second,
+}
+
+// retryableError a failed download attempt that can be resumed
+type retryableError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// downloadReleaseAsset downloads the asset into a .part file in the storage location, retrying failed attempts with
+// an exponential backoff. Retries resume the download using a HTTP Range request. Once the size matches the size of the
+// release asset, the .part file is renamed to the asset name.
+//
+// When digest algorithms are given, the download stream is hashed and the digests are returned. Without a storage
+// location the asset is only hashed, retries then restart the download from the beginning.
+func (p *ReleaseClient) downloadReleaseAsset(ctx context.Context, owner, repo string, asset *github.ReleaseAsset, storageLocation string, algorithms []string) (map[string]string, error) {
+	var dest, part string
+	if storageLocation != "" {
+		dest = path.Join(storageLocation, asset.GetName())
+		part = dest + ".part"
+	}
+
+	var (
+		size    int64
+		digests map[string]string
+		err     error
+	)
+	backoff := p.download.Backoff
+	for attempt := 0; ; attempt++ {
+		size, digests, err = p.downloadAttempt(ctx, owner, repo, asset, part, algorithms)
+		if err == nil {
+			break
+		}
+
+		var rerr *retryableError
+		if !errors.As(err, &rerr) || attempt >= p.download.Retries {
+			return nil, fmt.Errorf("failed to download release asset %s: %w", asset.GetName(), err)
+		}
+		wait := backoff
+		if rerr.retryAfter > 0 {
+			wait = rerr.retryAfter
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to download release asset %s: %w", asset.GetName(), ctx.Err())
+		case <-time.After(wait):
+		}
+		backoff *= 2
+	}
+
+	if size != int64(asset.GetSize()) {
+		if part != "" {
+			_ = os.Remove(part)
+		}
+		return nil, fmt.Errorf("failed to download release asset %s: expected %d bytes, got %d", asset.GetName(), asset.GetSize(), size)
+	}
+	if part != "" {
+		if err := os.Rename(part, dest); err != nil {
+			return nil, err
+		}
+	}
+	return digests, nil
+}
+
+// downloadAttempt downloads the asset to the .part file, resuming from the size of an existing .part file. It returns
+// the total size of the downloaded asset and, when digest algorithms are given, its digests.
+func (p *ReleaseClient) downloadAttempt(ctx context.Context, owner, repo string, asset *github.ReleaseAsset, part string, algorithms []string) (int64, map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.download.Timeout)
+	defer cancel()
+
+	var offset int64
+	if part != "" {
+		if info, err := os.Stat(part); err == nil {
+			offset = info.Size()
+		}
+	}
+	if offset > 0 && offset >= int64(asset.GetSize()) {
+		digests, err := partDigests(part, offset, algorithms)
+		return offset, digests, err
+	}
+
+	req, err := p.NewRequest(http.MethodGet, fmt.Sprintf("repos/%s/%s/releases/assets/%d", owner, repo, asset.GetID()), nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/octet-stream")
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	httpClient := p.httpClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, nil, &retryableError{err: err}
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		flags |= os.O_TRUNC
+		offset = 0
+	case resp.StatusCode == http.StatusPartialContent:
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// NOTE: the .part file doesn't match the asset anymore, start over.
+		_ = os.Remove(part)
+		return 0, nil, &retryableError{err: fmt.Errorf("unexpected status %s resuming at %d bytes", resp.Status, offset)}
+	case resp.StatusCode >= http.StatusInternalServerError, resp.StatusCode == http.StatusTooManyRequests:
+		return 0, nil, &retryableError{err: fmt.Errorf("unexpected status %s", resp.Status), retryAfter: retryAfter(resp)}
+	default:
+		return 0, nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var hasher *digest.Hasher
+	if len(algorithms) > 0 {
+		hasher, err = digest.NewHasher(int64(asset.GetSize()), algorithms...)
+		if err != nil {
+			return 0, nil, err
+		}
+		if offset > 0 {
+			if err := hashFile(hasher, part); err != nil {
+				return 0, nil, err
+			}
+		}
+	}
+
+	var w io.Writer = io.Discard
+	if part != "" {
+		f, err := os.OpenFile(part, flags, 0644)
+		if err != nil {
+			return 0, nil, err
+		}
+		defer f.Close()
+		w = f
+	}
+	if hasher != nil {
+		w = io.MultiWriter(w, hasher)
+	}
+
+	n, err := io.Copy(w, resp.Body)
+	if err != nil {
+		return 0, nil, &retryableError{err: err}
+	}
+	if hasher == nil || offset+n != int64(asset.GetSize()) {
+		return offset + n, nil, nil
+	}
+	digests, err := hasher.Digests()
+	if err != nil {
+		return 0, nil, err
+	}
+	return offset + n, digests, nil
+}
+
+// partDigests hashes a completely downloaded .part file
+func partDigests(part string, size int64, algorithms []string) (map[string]string, error) {
+	if len(algorithms) == 0 {
+		return nil, nil
+	}
+	hasher, err := digest.NewHasher(size, algorithms...)
+	if err != nil {
+		return nil, err
+	}
+	if err := hashFile(hasher, part); err != nil {
+		return nil, err
+	}
+	return hasher.Digests()
+}
+
+func hashFile(hasher *digest.Hasher, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(hasher, f)
+	return err
+}
+
+// retryAfter parses the Retry-After header in seconds, returns 0 when not set
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
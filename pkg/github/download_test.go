@@ -0,0 +1,238 @@
+package github_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	gh "github.com/google/go-github/v41/github"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/digest"
+	"github.com/philips-labs/slsa-provenance-action/pkg/github"
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+)
+
+type fakeAsset struct {
+	id      int64
+	name    string
+	content []byte
+	// size the size reported by the release API, defaults to the content length
+	size int
+	// failures the responses served before the asset is served
+	failures []func(w http.ResponseWriter, r *http.Request)
+}
+
+// fakeReleaseServer serves the release assets API for release 1 of owner/repo on GitHub Enterprise Server
+func fakeReleaseServer(t *testing.T, assets ...*fakeAsset) (*github.ReleaseClient, *[]string) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/owner/repo/releases/1/assets", func(w http.ResponseWriter, r *http.Request) {
+		var list []*gh.ReleaseAsset
+		for _, a := range assets {
+			size := a.size
+			if size == 0 {
+				size = len(a.content)
+			}
+			list = append(list, &gh.ReleaseAsset{ID: gh.Int64(a.id), Name: gh.String(a.name), Size: gh.Int(size)})
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	})
+	for _, a := range assets {
+		mux.HandleFunc(fmt.Sprintf("/api/v3/repos/owner/repo/releases/assets/%d", a.id), func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requests = append(requests, fmt.Sprintf("%s %s", a.name, r.Header.Get("Range")))
+			var failure func(w http.ResponseWriter, r *http.Request)
+			if len(a.failures) > 0 {
+				failure, a.failures = a.failures[0], a.failures[1:]
+			}
+			mu.Unlock()
+
+			if failure != nil {
+				failure(w, r)
+				return
+			}
+			http.ServeContent(w, r, a.name, time.Time{}, bytes.NewReader(a.content))
+		})
+	}
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	client, err := github.NewEnterpriseReleaseClient(srv.Client(), srv.URL+"/api/v3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.WithDownloadOptions(github.DownloadOptions{Concurrency: 2, Retries: 2, Backoff: time.Millisecond})
+	return client, &requests
+}
+
+func TestDownloadReleaseAssetsRetries(t *testing.T) {
+	assert := assert.New(t)
+
+	content := bytes.Repeat([]byte("salsa"), 1024)
+	truncated := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+		_, _ = w.Write(content[:1000])
+		w.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	}
+	unavailable := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	rateLimited := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}
+
+	client, requests := fakeReleaseServer(t,
+		&fakeAsset{id: 10, name: "checksums.txt", content: []byte("checksums"), failures: []func(http.ResponseWriter, *http.Request){unavailable, rateLimited}},
+		&fakeAsset{id: 11, name: "salsa.tar.gz", content: content, failures: []func(http.ResponseWriter, *http.Request){truncated}},
+	)
+
+	artifactPath := path.Join(t.TempDir(), "assets")
+	assets, err := client.DownloadReleaseAssets(context.Background(), "owner", "repo", 1, artifactPath)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Len(assets, 2)
+
+	downloaded, err := os.ReadFile(path.Join(artifactPath, "salsa.tar.gz"))
+	assert.NoError(err)
+	assert.Equal(content, downloaded)
+	assert.NoFileExists(path.Join(artifactPath, "salsa.tar.gz.part"))
+	assert.FileExists(path.Join(artifactPath, "checksums.txt"))
+
+	assert.ElementsMatch([]string{
+		"checksums.txt ", "checksums.txt ", "checksums.txt ",
+		"salsa.tar.gz ", "salsa.tar.gz bytes=1000-",
+	}, *requests)
+}
+
+func TestDownloadReleaseAssetsFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	unavailable := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}
+	notFound := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}
+
+	client, _ := fakeReleaseServer(t,
+		&fakeAsset{id: 10, name: "salsa.txt", content: []byte("salsa"), size: 10},
+	)
+	_, err := client.DownloadReleaseAssets(context.Background(), "owner", "repo", 1, t.TempDir())
+	assert.EqualError(err, "failed to download release asset salsa.txt: expected 10 bytes, got 5")
+
+	client, _ = fakeReleaseServer(t,
+		&fakeAsset{id: 10, name: "salsa.txt", content: []byte("salsa"), failures: []func(http.ResponseWriter, *http.Request){unavailable, unavailable, unavailable}},
+	)
+	_, err = client.DownloadReleaseAssets(context.Background(), "owner", "repo", 1, t.TempDir())
+	assert.EqualError(err, "failed to download release asset salsa.txt: unexpected status 502 Bad Gateway")
+
+	client, requests := fakeReleaseServer(t,
+		&fakeAsset{id: 10, name: "salsa.txt", content: []byte("salsa"), failures: []func(http.ResponseWriter, *http.Request){notFound}},
+	)
+	_, err = client.DownloadReleaseAssets(context.Background(), "owner", "repo", 1, t.TempDir())
+	assert.EqualError(err, "failed to download release asset salsa.txt: unexpected status 404 Not Found")
+	assert.Len(*requests, 1)
+}
+
+func TestDownloadAndHashReleaseAssets(t *testing.T) {
+	assert := assert.New(t)
+
+	content := bytes.Repeat([]byte("salsa"), 1024)
+	truncated := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+		_, _ = w.Write(content[:1000])
+		w.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	}
+	expected := []intoto.Subject{
+		{Name: "checksums.txt", Digest: intoto.DigestSet{"sha256": digest.SHA256Hex([]byte("checksums"))}},
+		{Name: "salsa.tar.gz", Digest: intoto.DigestSet{"sha256": digest.SHA256Hex(content)}},
+	}
+
+	client, requests := fakeReleaseServer(t,
+		&fakeAsset{id: 11, name: "salsa.tar.gz", content: content, failures: []func(http.ResponseWriter, *http.Request){truncated}},
+		&fakeAsset{id: 10, name: "checksums.txt", content: []byte("checksums")},
+	)
+	artifactPath := path.Join(t.TempDir(), "assets")
+	subjects, err := client.DownloadAndHashReleaseAssets(context.Background(), "owner", "repo", 1, artifactPath)
+	assert.NoError(err)
+	assert.Equal(expected, subjects)
+	assert.FileExists(path.Join(artifactPath, "salsa.tar.gz"))
+	assert.Contains(*requests, "salsa.tar.gz bytes=1000-")
+
+	client, requests = fakeReleaseServer(t,
+		&fakeAsset{id: 11, name: "salsa.tar.gz", content: content, failures: []func(http.ResponseWriter, *http.Request){truncated}},
+		&fakeAsset{id: 10, name: "checksums.txt", content: []byte("checksums")},
+	)
+	subjects, err = client.DownloadAndHashReleaseAssets(context.Background(), "owner", "repo", 1, "")
+	assert.NoError(err)
+	assert.Equal(expected, subjects)
+	assert.ElementsMatch([]string{"checksums.txt ", "salsa.tar.gz ", "salsa.tar.gz "}, *requests)
+
+	client, _ = fakeReleaseServer(t,
+		&fakeAsset{id: 10, name: "checksums.txt", content: []byte("checksums")},
+	)
+	subjects, err = client.DownloadAndHashReleaseAssets(context.Background(), "owner", "repo", 1, "", digest.SHA512, digest.GitoidBlobSHA1)
+	assert.NoError(err)
+	if assert.Len(subjects, 1) {
+		assert.Len(subjects[0].Digest, 2)
+	}
+}
+
+func TestDownloadReleaseAssetsFilter(t *testing.T) {
+	assert := assert.New(t)
+
+	client, requests := fakeReleaseServer(t,
+		&fakeAsset{id: 10, name: "salsa.tar.gz", content: []byte("salsa")},
+		&fakeAsset{id: 11, name: "salsa.tar.gz.sig", content: []byte("sig")},
+		&fakeAsset{id: 12, name: "provenance.json", content: []byte("{}")},
+		&fakeAsset{id: 13, name: "checksums.txt", content: []byte("checksums")},
+	)
+	client.WithAssetFilter(github.AssetFilter{Include: []string{"*.tar.gz*", "provenance.json"}, Exclude: []string{"*.sig", "provenance*.json"}})
+
+	artifactPath := t.TempDir()
+	assets, err := client.DownloadReleaseAssets(context.Background(), "owner", "repo", 1, artifactPath)
+	assert.NoError(err)
+	if assert.Len(assets, 1) {
+		assert.Equal("salsa.tar.gz", assets[0].GetName())
+	}
+	assert.Equal([]string{"salsa.tar.gz "}, *requests)
+	assert.NoFileExists(path.Join(artifactPath, "salsa.tar.gz.sig"))
+
+	subjects, err := client.DownloadAndHashReleaseAssets(context.Background(), "owner", "repo", 1, "")
+	assert.NoError(err)
+	assert.Equal([]intoto.Subject{{Name: "salsa.tar.gz", Digest: intoto.DigestSet{"sha256": digest.SHA256Hex([]byte("salsa"))}}}, subjects)
+}
+
+func TestAssetFilter(t *testing.T) {
+	assert := assert.New(t)
+
+	filter := github.AssetFilter{}
+	assert.True(filter.Match("salsa.tar.gz"))
+
+	filter = github.AssetFilter{Exclude: []string{"*.sig", "*.pem"}}
+	assert.True(filter.Match("salsa.tar.gz"))
+	assert.False(filter.Match("salsa.tar.gz.sig"))
+	assert.False(filter.Match("cert.pem"))
+
+	filter = github.AssetFilter{Include: []string{"*.tar.gz", "*.zip"}, Exclude: []string{"*-src.*"}}
+	assert.True(filter.Match("salsa.zip"))
+	assert.False(filter.Match("checksums.txt"))
+	assert.False(filter.Match("salsa-src.tar.gz"))
+}
@@ -2,7 +2,11 @@ package github
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+	"strings"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
 )
 
 const (
@@ -12,22 +16,31 @@ const (
 	SelfHostedIDSuffix = "/Attestations/SelfHostedActions@v1"
 	// BuildType URI indicating what type of build was performed. It determines the meaning of invocation, buildConfig and materials.
 	BuildType = "https://github.com/Attestations/GitHubActionsWorkflow@v1"
+	// BuildTypeV1 URI indicating what type of build was performed for SLSA v1.0 provenance. It determines the meaning of externalParameters and internalParameters.
+	BuildTypeV1 = "https://slsa-framework.github.io/github-actions-buildtypes/workflow/v1"
+	// DefaultServerURL the URL of the GitHub server used when the context has no server_url
+	DefaultServerURL = "https://github.com"
+	// DefaultAPIURL the URL of the GitHub API used when the context has no api_url
+	DefaultAPIURL = "https://api.github.com"
 	// PayloadContentType used to define the Envelope content type
 	// See: https://github.com/in-toto/attestation#provenance-example
-	PayloadContentType = "application/vnd.in-toto+json"
+	PayloadContentType = intoto.PayloadType
 )
 
 func builderID(repoURI string) string {
-	if os.Getenv("GITHUB_ACTIONS") == "true" {
+	if IsGitHubActions(os.Getenv) {
 		return repoURI + HostedIDSuffix
 	}
 	return repoURI + SelfHostedIDSuffix
 }
 
 // Environment the environment from which provenance is generated.
+//
+// PredicateVersion selects the SLSA provenance predicate to generate, defaults to intoto.PredicateVersionV02.
 type Environment struct {
-	Context *Context       `json:"github,omitempty"`
-	Runner  *RunnerContext `json:"runner,omitempty"`
+	Context          *Context       `json:"github,omitempty"`
+	Runner           *RunnerContext `json:"runner,omitempty"`
+	PredicateVersion string         `json:"-"`
 }
 
 // Context holds all the information set on Github runners in relation to the job
@@ -43,17 +56,141 @@ type Context struct {
 	EventPath       string          `json:"event_path"`
 	HeadRef         string          `json:"head_ref"`
 	Job             string          `json:"job"`
+	JobWorkflowRef  string          `json:"job_workflow_ref"`
 	Ref             string          `json:"ref"`
 	Repository      string          `json:"repository"`
 	RepositoryOwner string          `json:"repository_owner"`
+	RunAttempt      string          `json:"run_attempt"`
 	RunID           string          `json:"run_id"`
 	RunNumber       string          `json:"run_number"`
 	SHA             string          `json:"sha"`
+	ServerURL       string          `json:"server_url"`
+	APIURL          string          `json:"api_url"`
 	Token           Token           `json:"token,omitempty"`
+	TriggeringActor string          `json:"triggering_actor"`
 	Workflow        string          `json:"workflow"`
+	WorkflowRef     string          `json:"workflow_ref"`
+	WorkflowSHA     string          `json:"workflow_sha"`
 	Workspace       string          `json:"workspace"`
 }
 
+// RepositoryURL the URL of the repository on the GitHub server, e.g. https://github.com/owner/repo or
+// https://github.example.com/owner/repo for GitHub Enterprise Server
+func (c *Context) RepositoryURL() string {
+	serverURL := c.ServerURL
+	if serverURL == "" {
+		serverURL = DefaultServerURL
+	}
+	return strings.TrimSuffix(serverURL, "/") + "/" + c.Repository
+}
+
+// ServerURLFromAPIURL derives the GitHub server URL from the API URL, e.g. https://github.example.com/api/v3
+// results in https://github.example.com
+func ServerURLFromAPIURL(apiURL string) string {
+	apiURL = strings.TrimSuffix(apiURL, "/")
+	if apiURL == DefaultAPIURL {
+		return DefaultServerURL
+	}
+	return strings.TrimSuffix(apiURL, "/api/v3")
+}
+
+// WorkflowPath the path of the workflow file that was triggered, e.g. .github/workflows/build.yml
+//
+// The path is taken from the workflow_ref (e.g. owner/repo/.github/workflows/build.yml@refs/heads/main), which in a
+// reusable workflow refers to the calling workflow. Falls back to the action_path when the workflow_ref is unknown.
+func (c *Context) WorkflowPath() string {
+	if c.WorkflowRef == "" {
+		return c.ActionPath
+	}
+	workflowPath := c.WorkflowRef
+	if i := strings.LastIndex(workflowPath, "@"); i >= 0 {
+		workflowPath = workflowPath[:i]
+	}
+	return strings.TrimPrefix(workflowPath, c.Repository+"/")
+}
+
+// WorkflowGitRef the git ref the workflow file was taken from, e.g. refs/tags/v0.1.0
+//
+// The ref is taken from the workflow_ref (e.g. owner/repo/.github/workflows/build.yml@refs/tags/v0.1.0), which can
+// differ from the ref that triggered the workflow run. Falls back to the ref when the workflow_ref is unknown.
+func (c *Context) WorkflowGitRef() string {
+	if i := strings.LastIndex(c.WorkflowRef, "@"); i >= 0 {
+		return c.WorkflowRef[i+1:]
+	}
+	return c.Ref
+}
+
+// IsGitHubActions reports whether the process runs in a GitHub Actions workflow
+func IsGitHubActions(getenv func(string) string) bool {
+	return getenv("GITHUB_ACTIONS") == "true"
+}
+
+// ContextFromEnv reads the Context from the GITHUB_* default environment variables using getenv, e.g. os.Getenv.
+// The event payload is read from the file at GITHUB_EVENT_PATH. It fails when one of GITHUB_REPOSITORY,
+// GITHUB_SHA, GITHUB_RUN_ID or GITHUB_EVENT_PATH is not set.
+//
+// See https://docs.github.com/en/actions/learn-github-actions/variables#default-environment-variables
+func ContextFromEnv(getenv func(string) string) (*Context, error) {
+	var missing []string
+	required := func(key string) string {
+		v := getenv(key)
+		if v == "" {
+			missing = append(missing, key)
+		}
+		return v
+	}
+
+	c := &Context{
+		Action:          getenv("GITHUB_ACTION"),
+		ActionPath:      getenv("GITHUB_ACTION_PATH"),
+		Actor:           getenv("GITHUB_ACTOR"),
+		BaseRef:         getenv("GITHUB_BASE_REF"),
+		EventName:       getenv("GITHUB_EVENT_NAME"),
+		EventPath:       required("GITHUB_EVENT_PATH"),
+		HeadRef:         getenv("GITHUB_HEAD_REF"),
+		Job:             getenv("GITHUB_JOB"),
+		Ref:             getenv("GITHUB_REF"),
+		Repository:      required("GITHUB_REPOSITORY"),
+		RepositoryOwner: getenv("GITHUB_REPOSITORY_OWNER"),
+		RunAttempt:      getenv("GITHUB_RUN_ATTEMPT"),
+		RunID:           required("GITHUB_RUN_ID"),
+		RunNumber:       getenv("GITHUB_RUN_NUMBER"),
+		SHA:             required("GITHUB_SHA"),
+		ServerURL:       getenv("GITHUB_SERVER_URL"),
+		APIURL:          getenv("GITHUB_API_URL"),
+		Token:           Token(getenv("GITHUB_TOKEN")),
+		TriggeringActor: getenv("GITHUB_TRIGGERING_ACTOR"),
+		Workflow:        getenv("GITHUB_WORKFLOW"),
+		WorkflowRef:     getenv("GITHUB_WORKFLOW_REF"),
+		WorkflowSHA:     getenv("GITHUB_WORKFLOW_SHA"),
+		Workspace:       getenv("GITHUB_WORKSPACE"),
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing GitHub Actions environment variables: %s", strings.Join(missing, ", "))
+	}
+
+	if c.RepositoryOwner == "" {
+		c.RepositoryOwner = strings.SplitN(c.Repository, "/", 2)[0]
+	}
+
+	event, err := os.ReadFile(c.EventPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read github event: %w", err)
+	}
+	c.Event = event
+
+	return c, nil
+}
+
+// RunnerContextFromEnv reads the RunnerContext from the RUNNER_* default environment variables using getenv, e.g. os.Getenv.
+func RunnerContextFromEnv(getenv func(string) string) *RunnerContext {
+	return &RunnerContext{
+		OS:        getenv("RUNNER_OS"),
+		Temp:      getenv("RUNNER_TEMP"),
+		ToolCache: getenv("RUNNER_TOOL_CACHE"),
+	}
+}
+
 // Token the github token used during a workflow
 type Token string
 
@@ -85,3 +222,33 @@ type RunnerContext struct {
 type AnyEvent struct {
 	Inputs json.RawMessage `json:"inputs"`
 }
+
+// WorkflowParameters the externalParameters of the SLSA v1.0 GitHub Actions workflow buildType
+//
+// See https://slsa-framework.github.io/github-actions-buildtypes/workflow/v1
+type WorkflowParameters struct {
+	Workflow Workflow        `json:"workflow"`
+	Inputs   json.RawMessage `json:"inputs,omitempty"`
+}
+
+// Workflow identifies the workflow that was executed
+type Workflow struct {
+	Ref        string `json:"ref"`
+	Repository string `json:"repository"`
+	Path       string `json:"path"`
+}
+
+// InternalParameters the internalParameters of the SLSA v1.0 GitHub Actions workflow buildType
+type InternalParameters struct {
+	GitHub InternalGitHubParameters `json:"github"`
+}
+
+// InternalGitHubParameters holds the GitHub specific internalParameters
+type InternalGitHubParameters struct {
+	EventName       string `json:"event_name"`
+	RepositoryOwner string `json:"repository_owner"`
+	RunAttempt      string `json:"run_attempt,omitempty"`
+	JobWorkflowRef  string `json:"job_workflow_ref,omitempty"`
+	WorkflowSHA     string `json:"workflow_sha,omitempty"`
+	TriggeringActor string `json:"triggering_actor,omitempty"`
+}
@@ -2,6 +2,8 @@ package github_test
 
 import (
 	"encoding/json"
+	"os"
+	"path"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -24,3 +26,88 @@ func TestMarshalGitHubContext(t *testing.T) {
 	assert.NotContains(string(j), `"token":"superSecret"`)
 	assert.Contains(string(j), `"token":"***"`)
 }
+
+func TestContextFromEnv(t *testing.T) {
+	assert := assert.New(t)
+
+	eventPath := path.Join(t.TempDir(), "event.json")
+	assert.NoError(os.WriteFile(eventPath, []byte(`{"inputs":{"release":"v0.1.0"}}`), 0644))
+
+	env := map[string]string{
+		"GITHUB_REPOSITORY":   "philips-labs/slsa-provenance-action",
+		"GITHUB_SHA":          "c4f679f131dfb7f810fd411ac9475549d1c393df",
+		"GITHUB_REF":          "refs/heads/main",
+		"GITHUB_RUN_ID":       "1332651620",
+		"GITHUB_RUN_ATTEMPT":  "2",
+		"GITHUB_WORKFLOW_REF": "philips-labs/slsa-provenance-action/.github/workflows/ci.yml@refs/heads/main",
+		"GITHUB_EVENT_NAME":   "workflow_dispatch",
+		"GITHUB_EVENT_PATH":   eventPath,
+		"GITHUB_TOKEN":        "superSecret",
+		"GITHUB_SERVER_URL":   "https://github.example.com",
+		"GITHUB_API_URL":      "https://github.example.com/api/v3",
+		"RUNNER_OS":           "Linux",
+		"RUNNER_TEMP":         "/home/runner/work/_temp",
+	}
+	getenv := func(key string) string { return env[key] }
+
+	gh, err := github.ContextFromEnv(getenv)
+	assert.NoError(err)
+	assert.Equal("philips-labs", gh.RepositoryOwner)
+	assert.Equal("refs/heads/main", gh.Ref)
+	assert.Equal("2", gh.RunAttempt)
+	assert.Equal(".github/workflows/ci.yml", gh.WorkflowPath())
+	assert.Equal("workflow_dispatch", gh.EventName)
+	assert.JSONEq(`{"inputs":{"release":"v0.1.0"}}`, string(gh.Event))
+	assert.Equal(github.Token("superSecret"), gh.Token)
+	assert.Equal("https://github.example.com", gh.ServerURL)
+	assert.Equal("https://github.example.com/api/v3", gh.APIURL)
+
+	runner := github.RunnerContextFromEnv(getenv)
+	assert.Equal(&github.RunnerContext{OS: "Linux", Temp: "/home/runner/work/_temp"}, runner)
+
+	delete(env, "GITHUB_EVENT_PATH")
+	_, err = github.ContextFromEnv(getenv)
+	assert.EqualError(err, "missing GitHub Actions environment variables: GITHUB_EVENT_PATH")
+
+	env["GITHUB_EVENT_PATH"] = path.Join(t.TempDir(), "unknown.json")
+	_, err = github.ContextFromEnv(getenv)
+	assert.ErrorContains(err, "failed to read github event")
+
+	delete(env, "GITHUB_SHA")
+	delete(env, "GITHUB_RUN_ID")
+	_, err = github.ContextFromEnv(getenv)
+	assert.EqualError(err, "missing GitHub Actions environment variables: GITHUB_RUN_ID, GITHUB_SHA")
+}
+
+func TestWorkflowPath(t *testing.T) {
+	assert := assert.New(t)
+
+	gh := github.Context{Repository: "philips-labs/slsa-provenance-action", ActionPath: ".github/workflows/build.yml"}
+	assert.Equal(".github/workflows/build.yml", gh.WorkflowPath())
+
+	gh.WorkflowRef = "philips-labs/slsa-provenance-action/.github/workflows/release.yml@refs/tags/v0.1.0"
+	assert.Equal(".github/workflows/release.yml", gh.WorkflowPath())
+}
+
+func TestWorkflowGitRef(t *testing.T) {
+	assert := assert.New(t)
+
+	gh := github.Context{Repository: "philips-labs/slsa-provenance-action", Ref: "refs/heads/main"}
+	assert.Equal("refs/heads/main", gh.WorkflowGitRef())
+
+	gh.WorkflowRef = "philips-labs/slsa-provenance-action/.github/workflows/release.yml@refs/tags/v0.1.0"
+	assert.Equal("refs/tags/v0.1.0", gh.WorkflowGitRef())
+}
+
+func TestRepositoryURL(t *testing.T) {
+	assert := assert.New(t)
+
+	gh := github.Context{Repository: "philips-labs/slsa-provenance-action"}
+	assert.Equal("https://github.com/philips-labs/slsa-provenance-action", gh.RepositoryURL())
+
+	gh.ServerURL = "https://github.example.com/"
+	assert.Equal("https://github.example.com/philips-labs/slsa-provenance-action", gh.RepositoryURL())
+
+	assert.Equal("https://github.com", github.ServerURLFromAPIURL("https://api.github.com"))
+	assert.Equal("https://github.example.com", github.ServerURLFromAPIURL("https://github.example.com/api/v3/"))
+}
@@ -21,23 +21,24 @@ func (e *Environment) GenerateProvenanceStatement(ctx context.Context, subjecter
 		return nil, err
 	}
 
-	repoURI := "https://github.com/" + e.Context.Repository
+	repoURI := e.Context.RepositoryURL()
 
 	event := AnyEvent{}
 	if err := json.Unmarshal(e.Context.Event, &event); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal github context event json: %w", err)
 	}
 
+	if e.PredicateVersion == intoto.PredicateVersionV1 {
+		return e.provenanceStatementV1(subjects, repoURI, event, materials)
+	}
+
 	stmt := intoto.SLSAProvenanceStatement(
 		intoto.WithSubject(subjects),
 		intoto.WithBuilder(builderID(repoURI)),
-		// NOTE: Re-runs are not uniquely identified and can cause run ID collisions.
-		intoto.WithMetadata(fmt.Sprintf("%s/actions/runs/%s", repoURI, e.Context.RunID)),
-		// NOTE: This is inexact as multiple workflows in a repo can have the same name.
-		// See https://github.com/github/feedback/discussions/4188
+		intoto.WithMetadata(e.invocationID(repoURI)),
 		intoto.WithInvocation(
 			BuildType,
-			e.Context.ActionPath,
+			e.Context.WorkflowPath(),
 			nil,
 			event.Inputs,
 			[]intoto.Item{
@@ -50,20 +51,72 @@ func (e *Environment) GenerateProvenanceStatement(ctx context.Context, subjecter
 	return stmt, nil
 }
 
+func (e *Environment) provenanceStatementV1(subjects []intoto.Subject, repoURI string, event AnyEvent, materials []intoto.Item) (*intoto.Statement, error) {
+	externalParameters, err := json.Marshal(WorkflowParameters{
+		Workflow: Workflow{
+			Ref:        e.Context.WorkflowGitRef(),
+			Repository: repoURI,
+			Path:       e.Context.WorkflowPath(),
+		},
+		Inputs: event.Inputs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal external parameters: %w", err)
+	}
+
+	internalParameters, err := json.Marshal(InternalParameters{
+		GitHub: InternalGitHubParameters{
+			EventName:       e.Context.EventName,
+			RepositoryOwner: e.Context.RepositoryOwner,
+			RunAttempt:      e.Context.RunAttempt,
+			JobWorkflowRef:  e.Context.JobWorkflowRef,
+			WorkflowSHA:     e.Context.WorkflowSHA,
+			TriggeringActor: e.Context.TriggeringActor,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal internal parameters: %w", err)
+	}
+
+	stmt := intoto.SLSAProvenanceStatementV1(
+		intoto.WithSubject(subjects),
+		intoto.WithRunDetails(builderID(repoURI), e.invocationID(repoURI)),
+		intoto.WithBuildDefinition(
+			BuildTypeV1,
+			externalParameters,
+			internalParameters,
+			[]intoto.ResourceDescriptor{
+				{URI: intoto.SourceURI(repoURI, e.Context.Ref), Digest: intoto.DigestSet{"gitCommit": e.Context.SHA}},
+			},
+		),
+		intoto.WithMaterials(materials),
+	)
+
+	return stmt, nil
+}
+
+// invocationID identifies the workflow run attempt, e.g. https://github.com/owner/repo/actions/runs/1029384756/attempts/2
+//
+// NOTE: Without the run_attempt (e.g. older runners) re-runs are not uniquely identified and can cause run ID collisions.
+func (e *Environment) invocationID(repoURI string) string {
+	runURI := fmt.Sprintf("%s/actions/runs/%s", repoURI, e.Context.RunID)
+	if e.Context.RunAttempt == "" {
+		return runURI
+	}
+	return fmt.Sprintf("%s/attempts/%s", runURI, e.Context.RunAttempt)
+}
+
 // PersistProvenanceStatement writes the provenance statement at the given path
 func (e *Environment) PersistProvenanceStatement(ctx context.Context, stmt *intoto.Statement, path string) error {
 	// NOTE: At L1, writing the in-toto Statement type is sufficient but, at
 	// higher SLSA levels, the Statement must be encoded and wrapped in an
-	// Envelope to support attaching signatures.
-	payload, err := json.MarshalIndent(stmt, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal provenance: %w", err)
-	}
-	if err := os.WriteFile(path, payload, 0755); err != nil {
-		return fmt.Errorf("failed to write provenance: %w", err)
-	}
+	// Envelope to support attaching signatures. See PersistProvenanceEnvelope.
+	return intoto.WriteProvenance(stmt, path)
+}
 
-	return nil
+// PersistProvenanceEnvelope writes the DSSE envelope wrapping the provenance statement at the given path
+func (e *Environment) PersistProvenanceEnvelope(ctx context.Context, env *intoto.Envelope, path string) error {
+	return intoto.WriteProvenance(env, path)
 }
 
 // ReleaseEnvironment implements intoto.Provenancer to Generate provenance based on a GitHub release
@@ -73,6 +126,7 @@ type ReleaseEnvironment struct {
 	tagName      string
 	releaseID    int64
 	artifactPath string
+	skipped      []string
 }
 
 // NewReleaseEnvironment creates a new instance of ReleaseEnvironment with the given tagName and provenanceClient
@@ -89,10 +143,23 @@ func NewReleaseEnvironment(gh Context, runner RunnerContext, tagName string, rc
 	}
 }
 
+// WithReleaseID sets the ID of the release, instead of fetching the release by its tagName
+func (e *ReleaseEnvironment) WithReleaseID(releaseID int64) *ReleaseEnvironment {
+	e.releaseID = releaseID
+	return e
+}
+
 // GenerateProvenanceStatement generates provenance from the GitHub release environment
 // Release assets will be downloaded to the given artifactPath
 // The artifactPath has to be a directory.
+//
+// A ReleaseAssetSubjecter hashes the release assets while downloading them, the assets are then only stored when an
+// artifactPath is given.
 func (e *ReleaseEnvironment) GenerateProvenanceStatement(ctx context.Context, subjecter intoto.Subjecter, materials ...intoto.Item) (*intoto.Statement, error) {
+	if _, ok := subjecter.(*ReleaseAssetSubjecter); ok && e.artifactPath == "" {
+		return e.Environment.GenerateProvenanceStatement(ctx, subjecter, materials...)
+	}
+
 	err := os.MkdirAll(e.artifactPath, 0755)
 	if err != nil {
 		return nil, err
@@ -105,6 +172,10 @@ func (e *ReleaseEnvironment) GenerateProvenanceStatement(ctx context.Context, su
 		return nil, errors.New("artifactPath has to be an empty directory")
 	}
 
+	if _, ok := subjecter.(*ReleaseAssetSubjecter); ok {
+		return e.Environment.GenerateProvenanceStatement(ctx, subjecter, materials...)
+	}
+
 	owner := e.Context.RepositoryOwner
 	repo := repositoryName(e.Context.Repository)
 
@@ -112,14 +183,56 @@ func (e *ReleaseEnvironment) GenerateProvenanceStatement(ctx context.Context, su
 	if err != nil {
 		return nil, err
 	}
-	_, err = e.rc.DownloadReleaseAssets(ctx, owner, repo, releaseID, e.artifactPath)
+	_, skipped, _, err := e.rc.downloadReleaseAssets(ctx, owner, repo, releaseID, e.artifactPath, nil)
 	if err != nil {
 		return nil, err
 	}
+	e.skipped = skipped
 
 	return e.Environment.GenerateProvenanceStatement(ctx, subjecter, materials...)
 }
 
+// ReleaseAssetSubjecter implements intoto.Subjecter to hash the release assets while downloading them
+type ReleaseAssetSubjecter struct {
+	ctx        context.Context
+	env        *ReleaseEnvironment
+	algorithms []string
+}
+
+// NewReleaseAssetSubjecter creates a new instance of ReleaseAssetSubjecter for the release of the environment.
+// The assets are stored at the artifactPath of the environment, an empty artifactPath only hashes the assets.
+func (e *ReleaseEnvironment) NewReleaseAssetSubjecter(ctx context.Context, algorithms ...string) *ReleaseAssetSubjecter {
+	return &ReleaseAssetSubjecter{
+		ctx:        ctx,
+		env:        e,
+		algorithms: algorithms,
+	}
+}
+
+// Subjects downloads the release assets and returns the subjects with the digests of the download streams
+func (s *ReleaseAssetSubjecter) Subjects() ([]intoto.Subject, error) {
+	owner := s.env.Context.RepositoryOwner
+	repo := repositoryName(s.env.Context.Repository)
+
+	releaseID, err := s.env.GetReleaseID(s.ctx, s.env.tagName)
+	if err != nil {
+		return nil, err
+	}
+
+	subjects, skipped, err := s.env.rc.downloadAndHashReleaseAssets(s.ctx, owner, repo, releaseID, s.env.artifactPath, s.algorithms)
+	if err != nil {
+		return nil, err
+	}
+	s.env.skipped = skipped
+
+	return subjects, nil
+}
+
+// SkippedAssets the names of the release assets skipped by the AssetFilter of the ReleaseClient
+func (e *ReleaseEnvironment) SkippedAssets() []string {
+	return e.skipped
+}
+
 // PersistProvenanceStatement writes the provenance statement at the given path and uploads it to the GitHub release
 func (e *ReleaseEnvironment) PersistProvenanceStatement(ctx context.Context, stmt *intoto.Statement, path string) error {
 	err := e.Environment.PersistProvenanceStatement(ctx, stmt, path)
@@ -127,15 +240,47 @@ func (e *ReleaseEnvironment) PersistProvenanceStatement(ctx context.Context, stm
 		return err
 	}
 
-	stmtFile, err := os.Open(path)
+	return e.uploadProvenance(ctx, path, StatementMediaType)
+}
+
+// PersistProvenanceEnvelope writes the provenance envelope at the given path and uploads it to the GitHub release
+// The signatures of the envelope are also uploaded as detached signatures, e.g. provenance.json.sig
+func (e *ReleaseEnvironment) PersistProvenanceEnvelope(ctx context.Context, env *intoto.Envelope, path string) error {
+	err := e.Environment.PersistProvenanceEnvelope(ctx, env, path)
+	if err != nil {
+		return err
+	}
+
+	if err := e.uploadProvenance(ctx, path, EnvelopeMediaType); err != nil {
+		return err
+	}
+
+	for i, sig := range env.Signatures {
+		sigPath := path + ".sig"
+		if i > 0 {
+			sigPath = fmt.Sprintf("%s.%d.sig", path, i+1)
+		}
+		if err := os.WriteFile(sigPath, []byte(sig.Sig), 0644); err != nil {
+			return fmt.Errorf("failed to write signature: %w", err)
+		}
+		if err := e.uploadProvenance(ctx, sigPath, SignatureMediaType); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *ReleaseEnvironment) uploadProvenance(ctx context.Context, path, mediaType string) error {
+	provenanceFile, err := os.Open(path)
 	if err != nil {
-		return fmt.Errorf("failed to open provenance statement: %w", err)
+		return fmt.Errorf("failed to open provenance: %w", err)
 	}
-	defer stmtFile.Close()
+	defer provenanceFile.Close()
 
 	owner := e.Context.RepositoryOwner
 	repo := repositoryName(e.Context.Repository)
-	_, err = e.rc.AddProvenanceToRelease(ctx, owner, repo, e.releaseID, stmtFile)
+	_, err = e.rc.AddReleaseAsset(ctx, owner, repo, e.releaseID, provenanceFile, mediaType)
 	if err != nil {
 		return fmt.Errorf("failed to upload provenance to release: %w", err)
 	}
@@ -2,6 +2,7 @@ package github_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/philips-labs/slsa-provenance-action/pkg/digest"
 	"github.com/philips-labs/slsa-provenance-action/pkg/github"
 	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
 )
@@ -283,6 +285,182 @@ func TestGenerateProvenance(t *testing.T) {
 	assertInvocation(assert, predicate.Invocation)
 }
 
+func TestGenerateProvenanceV1(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+	os.Setenv("GITHUB_ACTIONS", "true")
+
+	repoURL := "https://github.com/philips-labs/slsa-provenance-action"
+
+	gh := github.Context{
+		RunID:           "1029384756",
+		Ref:             "refs/heads/main",
+		RepositoryOwner: "philips-labs",
+		Repository:      "philips-labs/slsa-provenance-action",
+		Event:           []byte(pushGitHubEvent),
+		EventName:       "push",
+		ActionPath:      ".github/workflows/build.yml",
+		SHA:             "849fb987efc0c0fc72e26a38f63f0c00225132be",
+	}
+	materials := []intoto.Item{
+		{URI: "pkg:deb/debian/stunnel4@5.50-3?arch=amd64", Digest: intoto.DigestSet{"sha256": "e1731ae217fcbc64d4c00d707dcead45c828c5f762bcf8cc56d87de511e096fa"}},
+	}
+
+	_, filename, _, _ := runtime.Caller(0)
+	rootDir := path.Join(path.Dir(filename), "../..")
+	artifactPath := path.Join(rootDir, "bin")
+	fps := intoto.NewFilePathSubjecter(artifactPath)
+
+	env := github.Environment{
+		Context:          &gh,
+		Runner:           &github.RunnerContext{},
+		PredicateVersion: intoto.PredicateVersionV1,
+	}
+	stmt, err := env.GenerateProvenanceStatement(ctx, fps, materials...)
+	if !assert.NoError(err) || !assert.NotNil(stmt.PredicateV1) {
+		return
+	}
+
+	binaryName := "slsa-provenance"
+	assert.Len(stmt.Subject, 1)
+	assertSubject(assert, stmt.Subject, binaryName, path.Join(artifactPath, binaryName))
+
+	assert.Equal(intoto.SlsaPredicateTypeV1, stmt.PredicateType)
+	assert.Equal(intoto.StatementTypeV1, stmt.Type)
+
+	bd := stmt.PredicateV1.BuildDefinition
+	assert.Equal(github.BuildTypeV1, bd.BuildType)
+	assert.JSONEq(fmt.Sprintf(`{"workflow":{"ref":"refs/heads/main","repository":"%s","path":".github/workflows/build.yml"}}`, repoURL), string(bd.ExternalParameters))
+	assert.JSONEq(`{"github":{"event_name":"push","repository_owner":"philips-labs"}}`, string(bd.InternalParameters))
+	assert.Equal([]intoto.ResourceDescriptor{
+		{URI: "git+" + repoURL + "@refs/heads/main", Digest: intoto.DigestSet{"gitCommit": gh.SHA}},
+		{URI: materials[0].URI, Digest: materials[0].Digest},
+	}, bd.ResolvedDependencies)
+
+	rd := stmt.PredicateV1.RunDetails
+	assert.Equal(fmt.Sprintf("%s%s", repoURL, github.HostedIDSuffix), rd.Builder.ID)
+	assert.Equal(fmt.Sprintf("%s/%s/%s", repoURL, "actions/runs", gh.RunID), rd.Metadata.InvocationID)
+	bft, err := time.Parse(time.RFC3339, rd.Metadata.FinishedOn)
+	assert.NoError(err)
+	assert.WithinDuration(time.Now().UTC(), bft, 1200*time.Millisecond)
+}
+
+func TestGenerateProvenanceReusableWorkflowRerun(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+	os.Setenv("GITHUB_ACTIONS", "true")
+
+	repoURL := "https://github.com/philips-labs/slsa-provenance-action"
+
+	gh := github.Context{
+		RunID:           "1029384756",
+		RunAttempt:      "2",
+		Ref:             "refs/heads/main",
+		RepositoryOwner: "philips-labs",
+		Repository:      "philips-labs/slsa-provenance-action",
+		Event:           []byte(pushGitHubEvent),
+		EventName:       "push",
+		ActionPath:      "/home/runner/work/_actions/philips-labs/slsa-provenance-action/v0.7.2",
+		SHA:             "849fb987efc0c0fc72e26a38f63f0c00225132be",
+		WorkflowRef:     "philips-labs/slsa-provenance-action/.github/workflows/release.yml@refs/heads/release",
+		WorkflowSHA:     "0c3bd1a3d4bd2b4f3e63b5f2a1f0b61f2a4f2d3e",
+		JobWorkflowRef:  "philips-labs/workflows/.github/workflows/build.yml@refs/tags/v1",
+		TriggeringActor: "john-doe",
+	}
+
+	_, filename, _, _ := runtime.Caller(0)
+	rootDir := path.Join(path.Dir(filename), "../..")
+	fps := intoto.NewFilePathSubjecter(path.Join(rootDir, "bin"))
+
+	env := github.Environment{Context: &gh, Runner: &github.RunnerContext{}}
+	stmt, err := env.GenerateProvenanceStatement(ctx, fps)
+	if !assert.NoError(err) {
+		return
+	}
+	invocationID := repoURL + "/actions/runs/1029384756/attempts/2"
+	assert.Equal(invocationID, stmt.Predicate.Metadata.BuildInvocationID)
+	assert.Equal(".github/workflows/release.yml", stmt.Predicate.Invocation.ConfigSource.EntryPoint)
+
+	env.PredicateVersion = intoto.PredicateVersionV1
+	stmt, err = env.GenerateProvenanceStatement(ctx, fps)
+	if !assert.NoError(err) {
+		return
+	}
+	bd := stmt.PredicateV1.BuildDefinition
+	assert.Equal(invocationID, stmt.PredicateV1.RunDetails.Metadata.InvocationID)
+	assert.JSONEq(fmt.Sprintf(`{"workflow":{"ref":"refs/heads/release","repository":"%s","path":".github/workflows/release.yml"}}`, repoURL), string(bd.ExternalParameters))
+	assert.JSONEq(`{"github":{"event_name":"push","repository_owner":"philips-labs","run_attempt":"2","job_workflow_ref":"philips-labs/workflows/.github/workflows/build.yml@refs/tags/v1","workflow_sha":"0c3bd1a3d4bd2b4f3e63b5f2a1f0b61f2a4f2d3e","triggering_actor":"john-doe"}}`, string(bd.InternalParameters))
+	assert.Equal(intoto.SourceURI(repoURL, "refs/heads/main"), bd.ResolvedDependencies[0].URI)
+}
+
+func TestGenerateProvenanceEnterpriseServer(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+	os.Setenv("GITHUB_ACTIONS", "true")
+
+	repoURL := "https://github.example.com/philips-labs/slsa-provenance-action"
+
+	gh := github.Context{
+		RunID:           "1029384756",
+		Ref:             "refs/heads/main",
+		RepositoryOwner: "philips-labs",
+		Repository:      "philips-labs/slsa-provenance-action",
+		Event:           []byte(pushGitHubEvent),
+		ActionPath:      ".github/workflows/build.yml",
+		SHA:             "849fb987efc0c0fc72e26a38f63f0c00225132be",
+		ServerURL:       "https://github.example.com",
+		APIURL:          "https://github.example.com/api/v3",
+	}
+
+	_, filename, _, _ := runtime.Caller(0)
+	rootDir := path.Join(path.Dir(filename), "../..")
+	fps := intoto.NewFilePathSubjecter(path.Join(rootDir, "bin"))
+
+	env := github.Environment{Context: &gh, Runner: &github.RunnerContext{}}
+	stmt, err := env.GenerateProvenanceStatement(ctx, fps)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal(repoURL+github.HostedIDSuffix, stmt.Predicate.Builder.ID)
+	assert.Equal(repoURL+"/actions/runs/1029384756", stmt.Predicate.Metadata.BuildInvocationID)
+	assert.Equal([]intoto.Item{
+		{URI: "git+" + repoURL, Digest: intoto.DigestSet{"sha1": gh.SHA}},
+	}, stmt.Predicate.Materials)
+}
+
+func TestPersistProvenanceEnvelope(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+	provenanceFile := path.Join(t.TempDir(), "provenance.json")
+
+	stmt := intoto.SLSAProvenanceStatement(
+		intoto.WithSubject([]intoto.Subject{{Name: "salsa.txt", Digest: intoto.DigestSet{"sha256": "f8161d035cdf328c7bb124fce192cb90b603f34ca78d73e33b736b4f6bddf993"}}}),
+	)
+	envelope, err := intoto.NewEnvelope(stmt)
+	if !assert.NoError(err) {
+		return
+	}
+
+	env := github.Environment{}
+	err = env.PersistProvenanceEnvelope(ctx, envelope, provenanceFile)
+	if !assert.NoError(err) {
+		return
+	}
+
+	content, err := os.ReadFile(provenanceFile)
+	assert.NoError(err)
+
+	var persisted intoto.Envelope
+	assert.NoError(json.Unmarshal(content, &persisted))
+	assert.Equal(github.PayloadContentType, persisted.PayloadType)
+	assert.Equal(envelope.Payload, persisted.Payload)
+	assert.Empty(persisted.Signatures)
+}
+
 func TestGenerateProvenanceFromGitHubRelease(t *testing.T) {
 	if tokenRetriever() == "" {
 		t.Skip("skipping as GITHUB_TOKEN environment variable isn't set")
@@ -426,6 +604,6 @@ func assertSubject(assert *assert.Assertions, subject []intoto.Subject, binaryNa
 		return
 	}
 
-	shaHex := intoto.ShaSum256HexEncoded(binary)
+	shaHex := digest.SHA256Hex(binary)
 	assert.Contains(subject, intoto.Subject{Name: binaryName, Digest: intoto.DigestSet{"sha256": shaHex}})
 }
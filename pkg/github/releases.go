@@ -2,15 +2,21 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
-	"path"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/google/go-github/v41/github"
 	"golang.org/x/oauth2"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/digest"
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
 )
 
 // TokenRetriever allows to implement a function to retrieve the token
@@ -23,10 +29,67 @@ func NewOAuth2Client(ctx context.Context, tokenRetriever TokenRetriever) *http.C
 	return oauth2.NewClient(ctx, ts)
 }
 
+const (
+	// StatementMediaType the media type of release assets containing an in-toto statement
+	StatementMediaType = intoto.PayloadType
+	// EnvelopeMediaType the media type of release assets containing a DSSE envelope
+	EnvelopeMediaType = "application/vnd.dsse.envelope.v1+json"
+	// SignatureMediaType the media type of release assets containing a base64 encoded detached signature
+	SignatureMediaType = "text/plain; charset=utf-8"
+)
+
+// ConflictPolicy determines how to upload a release asset when the release already has an asset with the same name
+type ConflictPolicy string
+
+const (
+	// ConflictFail fails the upload with ErrReleaseAssetExists
+	ConflictFail ConflictPolicy = "fail"
+	// ConflictReplace deletes the existing release asset before uploading
+	ConflictReplace ConflictPolicy = "replace"
+	// ConflictSkip keeps the existing release asset and skips the upload
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictSuffix uploads the release asset with a numbered suffix, e.g. provenance-1.json
+	ConflictSuffix ConflictPolicy = "suffix"
+)
+
+// ConflictPolicies the supported conflict policies
+var ConflictPolicies = []ConflictPolicy{ConflictFail, ConflictReplace, ConflictSkip, ConflictSuffix}
+
+// ErrReleaseAssetExists is returned when uploading a release asset with the name of an existing asset
+var ErrReleaseAssetExists = errors.New("release asset already exists")
+
+// AssetFilter selects the release assets by name using doublestar glob patterns, e.g. *.tar.gz
+// Excludes take precedence over includes, without includes all assets not excluded are selected.
+type AssetFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// Match reports whether the release asset name is included and not excluded
+func (f AssetFilter) Match(name string) bool {
+	for _, p := range f.Exclude {
+		if doublestar.MatchUnvalidated(p, name) {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, p := range f.Include {
+		if doublestar.MatchUnvalidated(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
 // ReleaseClient GitHub client adding convenience methods to add provenance to a release
 type ReleaseClient struct {
 	*github.Client
 	httpClient *http.Client
+	download   DownloadOptions
+	onConflict ConflictPolicy
+	filter     AssetFilter
 }
 
 // NewReleaseClient create new ReleaseClient instance
@@ -34,93 +97,243 @@ func NewReleaseClient(httpClient *http.Client) *ReleaseClient {
 	return &ReleaseClient{
 		Client:     github.NewClient(httpClient),
 		httpClient: httpClient,
+		download:   DefaultDownloadOptions,
+		onConflict: ConflictFail,
+	}
+}
+
+// WithDownloadOptions sets the options to download the release assets with. The Concurrency, Timeout and Backoff
+// default to the DefaultDownloadOptions when not set, a negative number of Retries defaults to the DefaultDownloadOptions.
+func (p *ReleaseClient) WithDownloadOptions(o DownloadOptions) *ReleaseClient {
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultDownloadOptions.Concurrency
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultDownloadOptions.Timeout
 	}
+	if o.Retries < 0 {
+		o.Retries = DefaultDownloadOptions.Retries
+	}
+	if o.Backoff <= 0 {
+		o.Backoff = DefaultDownloadOptions.Backoff
+	}
+	p.download = o
+	return p
+}
+
+// WithConflictPolicy sets the policy to upload release assets with when the release already has an asset with the
+// same name, defaults to ConflictFail.
+func (p *ReleaseClient) WithConflictPolicy(policy ConflictPolicy) *ReleaseClient {
+	if policy == "" {
+		policy = ConflictFail
+	}
+	p.onConflict = policy
+	return p
 }
 
-// FetchRelease get the release by its tagName
+// WithAssetFilter sets the filter selecting the release assets to download, the other assets are skipped.
+func (p *ReleaseClient) WithAssetFilter(filter AssetFilter) *ReleaseClient {
+	p.filter = filter
+	return p
+}
+
+// NewEnterpriseReleaseClient create new ReleaseClient instance for the GitHub API at apiURL, e.g. the
+// https://github.example.com/api/v3 API of GitHub Enterprise Server. Release assets are uploaded to the
+// corresponding uploads API. An empty apiURL targets github.com.
+func NewEnterpriseReleaseClient(httpClient *http.Client, apiURL string) (*ReleaseClient, error) {
+	apiURL = strings.TrimSuffix(apiURL, "/")
+	if apiURL == "" || apiURL == DefaultAPIURL {
+		return NewReleaseClient(httpClient), nil
+	}
+
+	client, err := github.NewEnterpriseClient(apiURL, ServerURLFromAPIURL(apiURL), httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("invalid github api url %s: %w", apiURL, err)
+	}
+	return &ReleaseClient{
+		Client:     client,
+		httpClient: httpClient,
+		download:   DefaultDownloadOptions,
+		onConflict: ConflictFail,
+	}, nil
+}
+
+// ErrReleaseNotFound is returned when there is no release for the given tag
+var ErrReleaseNotFound = errors.New("release not found")
+
+// FetchRelease get the release by its tagName. Draft releases can't be fetched by their tag, when the release isn't
+// found by its tag the releases are listed to find a draft release with the tagName.
+// Returns ErrReleaseNotFound when there is no release with the tagName.
 func (p *ReleaseClient) FetchRelease(ctx context.Context, owner, repo, tagName string) (*github.RepositoryRelease, error) {
-	listCtx, cancel := context.WithTimeout(ctx, 1*time.Minute)
+	fetchCtx, cancel := context.WithTimeout(ctx, 1*time.Minute)
 	defer cancel()
 
-	allReleases, err := p.ListReleases(listCtx, owner, repo, github.ListOptions{PerPage: 25})
+	rel, resp, err := p.Repositories.GetReleaseByTag(fetchCtx, owner, repo, tagName)
+	if err == nil {
+		return rel, nil
+	}
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		return nil, fmt.Errorf("failed to get release %s: %w", tagName, err)
+	}
+
+	allReleases, err := p.ListReleases(fetchCtx, owner, repo, github.ListOptions{PerPage: 25})
 	if err != nil {
 		return nil, err
 	}
-
-	var rel *github.RepositoryRelease
 	for _, r := range allReleases {
-		if *r.TagName == tagName {
-			rel = r
-			break
+		if r.GetTagName() == tagName {
+			return r, nil
 		}
 	}
 
-	return rel, nil
+	return nil, fmt.Errorf("%w: no release with tag %s in %s/%s", ErrReleaseNotFound, tagName, owner, repo)
 }
 
 // DownloadReleaseAssets download the assets for a release at the given storage location.
+//
+// The assets are downloaded in parallel, each download is retried and resumed on failures and verified
+// against the size of the release asset. Assets not matching the AssetFilter of the client are skipped.
 func (p *ReleaseClient) DownloadReleaseAssets(ctx context.Context, owner, repo string, releaseID int64, storageLocation string) ([]*github.ReleaseAsset, error) {
-	listCtx, cancel := context.WithTimeout(ctx, 1*time.Minute)
-	defer cancel()
+	assets, _, _, err := p.downloadReleaseAssets(ctx, owner, repo, releaseID, storageLocation, nil)
+	return assets, err
+}
 
-	allAssets, err := p.ListReleaseAssets(listCtx, owner, repo, releaseID, github.ListOptions{PerPage: 10})
+// DownloadAndHashReleaseAssets download the assets for a release and computes the digests of the download streams.
+// The assets are stored at the given storage location, an empty storage location only hashes the assets without
+// persisting them. When no algorithms are given the digest.Default algorithms are used.
+// Assets not matching the AssetFilter of the client are skipped.
+func (p *ReleaseClient) DownloadAndHashReleaseAssets(ctx context.Context, owner, repo string, releaseID int64, storageLocation string, algorithms ...string) ([]intoto.Subject, error) {
+	subjects, _, err := p.downloadAndHashReleaseAssets(ctx, owner, repo, releaseID, storageLocation, algorithms)
+	return subjects, err
+}
+
+// downloadAndHashReleaseAssets returns the subjects of the downloaded assets and the skipped assets
+func (p *ReleaseClient) downloadAndHashReleaseAssets(ctx context.Context, owner, repo string, releaseID int64, storageLocation string, algorithms []string) ([]intoto.Subject, []string, error) {
+	if len(algorithms) == 0 {
+		algorithms = digest.Default
+	}
+
+	assets, skipped, digests, err := p.downloadReleaseAssets(ctx, owner, repo, releaseID, storageLocation, algorithms)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	assets := make([]*github.ReleaseAsset, len(allAssets))
 
-	downloadCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	subjects := make([]intoto.Subject, 0, len(assets))
+	for i, asset := range assets {
+		subjects = append(subjects, intoto.Subject{Name: asset.GetName(), Digest: intoto.DigestSet(digests[i])})
+	}
+	sort.Slice(subjects, func(i, j int) bool { return subjects[i].Name < subjects[j].Name })
+
+	return subjects, skipped, nil
+}
+
+// downloadReleaseAssets returns the downloaded assets with their digests and the sorted names of the assets skipped
+// by the AssetFilter
+func (p *ReleaseClient) downloadReleaseAssets(ctx context.Context, owner, repo string, releaseID int64, storageLocation string, algorithms []string) ([]*github.ReleaseAsset, []string, []map[string]string, error) {
+	listCtx, cancel := context.WithTimeout(ctx, 1*time.Minute)
 	defer cancel()
 
-	err = os.MkdirAll(storageLocation, 0755)
+	allAssets, err := p.ListReleaseAssets(listCtx, owner, repo, releaseID, github.ListOptions{PerPage: 10})
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
-	for i, releaseAsset := range allAssets {
-		asset, _, err := p.Repositories.DownloadReleaseAsset(downloadCtx, owner, repo, releaseAsset.GetID(), p.httpClient)
-		if err != nil {
-			return nil, err
+	var assets []*github.ReleaseAsset
+	var skipped []string
+	for _, a := range allAssets {
+		if p.filter.Match(a.GetName()) {
+			assets = append(assets, a)
+		} else {
+			skipped = append(skipped, a.GetName())
 		}
-		err = saveFile(path.Join(storageLocation, releaseAsset.GetName()), asset)
+	}
+	sort.Strings(skipped)
+
+	if storageLocation != "" {
+		err = os.MkdirAll(storageLocation, 0755)
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, err
 		}
-		assets[i] = releaseAsset
 	}
 
-	return assets, nil
-}
-
-func saveFile(path string, content io.ReadCloser) error {
-	assetFile, err := os.Create(path)
-	if err != nil {
-		return err
+	digests := make([]map[string]string, len(assets))
+	g, downloadCtx := errgroup.WithContext(ctx)
+	g.SetLimit(p.download.Concurrency)
+	for i, releaseAsset := range assets {
+		g.Go(func() error {
+			d, err := p.downloadReleaseAsset(downloadCtx, owner, repo, releaseAsset, storageLocation, algorithms)
+			digests[i] = d
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, nil, nil, err
 	}
-	defer assetFile.Close()
-	defer content.Close()
-
-	_, err = io.Copy(assetFile, content)
 
-	return err
+	return assets, skipped, digests, nil
 }
 
 // AddProvenanceToRelease uploads the provenance for the given release
 func (p *ReleaseClient) AddProvenanceToRelease(ctx context.Context, owner, repo string, releaseID int64, provenance *os.File) (*github.ReleaseAsset, error) {
-	client := p.Client
+	return p.AddReleaseAsset(ctx, owner, repo, releaseID, provenance, "application/json; charset=utf-8")
+}
 
-	stat, err := provenance.Stat()
+// AddReleaseAsset uploads the file with the given media type to the release. When the release already has an asset
+// with the same name, the ConflictPolicy of the client determines whether the upload fails, replaces the existing
+// asset, is skipped or uses a suffixed name.
+func (p *ReleaseClient) AddReleaseAsset(ctx context.Context, owner, repo string, releaseID int64, file *os.File, mediaType string) (*github.ReleaseAsset, error) {
+	stat, err := file.Stat()
 	if err != nil {
 		return nil, err
 	}
-	uploadOptions := &github.UploadOptions{Name: stat.Name(), MediaType: "application/json; charset=utf-8"}
+	name := stat.Name()
+
 	uploadCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
 	defer cancel()
 
-	asset, _, err := client.Repositories.UploadReleaseAsset(uploadCtx, owner, repo, releaseID, uploadOptions, provenance)
+	assets, err := p.ListReleaseAssets(uploadCtx, owner, repo, releaseID, github.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]*github.ReleaseAsset, len(assets))
+	for _, a := range assets {
+		existing[a.GetName()] = a
+	}
+
+	if asset, ok := existing[name]; ok {
+		switch p.onConflict {
+		case ConflictSkip:
+			return asset, nil
+		case ConflictReplace:
+			if _, err := p.Repositories.DeleteReleaseAsset(uploadCtx, owner, repo, asset.GetID()); err != nil {
+				return nil, fmt.Errorf("failed to delete release asset %s: %w", name, err)
+			}
+		case ConflictSuffix:
+			name = suffixedName(name, existing)
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrReleaseAssetExists, name)
+		}
+	}
+
+	uploadOptions := &github.UploadOptions{Name: name, MediaType: mediaType}
+	asset, _, err := p.Repositories.UploadReleaseAsset(uploadCtx, owner, repo, releaseID, uploadOptions, file)
 	return asset, err
 }
 
+// suffixedName inserts the first free number before the extensions of the name, e.g. provenance-1.intoto.jsonl
+func suffixedName(name string, existing map[string]*github.ReleaseAsset) string {
+	base, ext := name, ""
+	if i := strings.Index(name, "."); i > 0 {
+		base, ext = name[:i], name[i:]
+	}
+	for n := 1; ; n++ {
+		candidate := fmt.Sprintf("%s-%d%s", base, n, ext)
+		if _, ok := existing[candidate]; !ok {
+			return candidate
+		}
+	}
+}
+
 // ListReleaseAssets will retrieve the list of all release assets.
 func (p *ReleaseClient) ListReleaseAssets(ctx context.Context, owner, repo string, releaseID int64, listOptions github.ListOptions) ([]*github.ReleaseAsset, error) {
 	var allAssets []*github.ReleaseAsset
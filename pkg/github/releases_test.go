@@ -2,8 +2,10 @@ package github_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"runtime"
@@ -59,7 +61,48 @@ func TestFetchRelease(t *testing.T) {
 	assert.Equal(int64(51517953), release.GetID())
 	assert.Equal(firstRelease, release.GetTagName())
 	assert.Len(release.Assets, 7)
-	assert.Equal(fmt.Sprintf("GET: %s?per_page=25\n", releasesAPI), requestLogger.String())
+	assert.Equal(fmt.Sprintf("GET: %s/tags/%s\n", releasesAPI, firstRelease), requestLogger.String())
+}
+
+func TestFetchReleaseDraft(t *testing.T) {
+	assert := assert.New(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/owner/repo/releases/tags/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/v1.0.0") {
+			_ = json.NewEncoder(w).Encode(&gh.RepositoryRelease{ID: gh.Int64(1), TagName: gh.String("v1.0.0")})
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/api/v3/repos/owner/repo/releases", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]*gh.RepositoryRelease{
+			{ID: gh.Int64(1), TagName: gh.String("v1.0.0")},
+			{ID: gh.Int64(2), TagName: gh.String("v1.1.0"), Draft: gh.Bool(true)},
+		})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx := context.Background()
+	client, err := github.NewEnterpriseReleaseClient(srv.Client(), srv.URL+"/api/v3")
+	if !assert.NoError(err) {
+		return
+	}
+
+	release, err := client.FetchRelease(ctx, "owner", "repo", "v1.0.0")
+	assert.NoError(err)
+	assert.Equal(int64(1), release.GetID())
+
+	release, err = client.FetchRelease(ctx, "owner", "repo", "v1.1.0")
+	assert.NoError(err)
+	assert.Equal(int64(2), release.GetID())
+	assert.True(release.GetDraft())
+
+	release, err = client.FetchRelease(ctx, "owner", "repo", "v2.0.0")
+	assert.ErrorIs(err, github.ErrReleaseNotFound)
+	assert.EqualError(err, "release not found: no release with tag v2.0.0 in owner/repo")
+	assert.Nil(release)
 }
 
 func TestDownloadReleaseAssets(t *testing.T) {
@@ -279,3 +322,91 @@ func createGitHubRelease(ctx context.Context, client *github.ReleaseClient, owne
 
 	return rel.GetID(), nil
 }
+
+func TestNewEnterpriseReleaseClient(t *testing.T) {
+	assert := assert.New(t)
+
+	client, err := github.NewEnterpriseReleaseClient(nil, "")
+	assert.NoError(err)
+	assert.Equal("https://api.github.com/", client.BaseURL.String())
+	assert.Equal("https://uploads.github.com/", client.UploadURL.String())
+
+	client, err = github.NewEnterpriseReleaseClient(nil, "https://github.example.com/api/v3")
+	assert.NoError(err)
+	assert.Equal("https://github.example.com/api/v3/", client.BaseURL.String())
+	assert.Equal("https://github.example.com/api/uploads/", client.UploadURL.String())
+
+	_, err = github.NewEnterpriseReleaseClient(nil, "://github.example.com")
+	assert.ErrorContains(err, "invalid github api url ://github.example.com")
+}
+
+func TestAddReleaseAssetConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   github.ConflictPolicy
+		existing []string
+		err      error
+		uploaded string
+		deleted  bool
+	}{
+		{name: "no conflict", policy: github.ConflictFail, existing: []string{"README.md"}, uploaded: "provenance.json"},
+		{name: "fail", policy: github.ConflictFail, existing: []string{"provenance.json"}, err: github.ErrReleaseAssetExists},
+		{name: "replace", policy: github.ConflictReplace, existing: []string{"provenance.json"}, uploaded: "provenance.json", deleted: true},
+		{name: "skip", policy: github.ConflictSkip, existing: []string{"provenance.json"}},
+		{name: "suffix", policy: github.ConflictSuffix, existing: []string{"provenance.json", "provenance-1.json"}, uploaded: "provenance-2.json"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var uploaded, mediaType string
+			var deleted bool
+			mux := http.NewServeMux()
+			mux.HandleFunc("/api/v3/repos/owner/repo/releases/1/assets", func(w http.ResponseWriter, r *http.Request) {
+				var assets []*gh.ReleaseAsset
+				for i, name := range tc.existing {
+					assets = append(assets, &gh.ReleaseAsset{ID: gh.Int64(int64(10 + i)), Name: gh.String(name)})
+				}
+				_ = json.NewEncoder(w).Encode(assets)
+			})
+			mux.HandleFunc("/api/v3/repos/owner/repo/releases/assets/10", func(w http.ResponseWriter, r *http.Request) {
+				deleted = r.Method == http.MethodDelete
+				w.WriteHeader(http.StatusNoContent)
+			})
+			mux.HandleFunc("/api/uploads/repos/owner/repo/releases/1/assets", func(w http.ResponseWriter, r *http.Request) {
+				uploaded = r.URL.Query().Get("name")
+				mediaType = r.Header.Get("Content-Type")
+				_ = json.NewEncoder(w).Encode(&gh.ReleaseAsset{ID: gh.Int64(20), Name: gh.String(uploaded)})
+			})
+			srv := httptest.NewServer(mux)
+			defer srv.Close()
+
+			client, err := github.NewEnterpriseReleaseClient(srv.Client(), srv.URL+"/api/v3")
+			if !assert.NoError(err) {
+				return
+			}
+			client.WithConflictPolicy(tc.policy)
+
+			provenance := path.Join(t.TempDir(), "provenance.json")
+			assert.NoError(os.WriteFile(provenance, []byte("{}"), 0644))
+			f, err := os.Open(provenance)
+			if !assert.NoError(err) {
+				return
+			}
+			defer f.Close()
+
+			_, err = client.AddReleaseAsset(context.Background(), "owner", "repo", 1, f, github.EnvelopeMediaType)
+			if tc.err != nil {
+				assert.ErrorIs(err, tc.err)
+			} else {
+				assert.NoError(err)
+			}
+			assert.Equal(tc.uploaded, uploaded)
+			if tc.uploaded != "" {
+				assert.Equal(github.EnvelopeMediaType, mediaType)
+			}
+			assert.Equal(tc.deleted, deleted)
+		})
+	}
+}
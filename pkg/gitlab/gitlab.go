@@ -0,0 +1,179 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	// RunnerIDInfix the path between the project URL and the runner ID in the builder ID, e.g. https://gitlab.com/owner/repo/-/runners/12270852
+	RunnerIDInfix = "/-/runners/"
+	// BuildType URI indicating what type of build was performed. It determines the meaning of invocation, buildConfig and materials.
+	BuildType = "https://github.com/philips-labs/slsa-provenance-action/Attestations/GitLabCIPipeline@v1"
+	// BuildTypeV1 URI indicating what type of build was performed for SLSA v1.0 provenance. It determines the meaning of externalParameters and internalParameters.
+	BuildTypeV1 = "https://github.com/philips-labs/slsa-provenance-action/buildtypes/gitlab-ci/v1"
+	// DefaultConfigPath the pipeline configuration used when CI_CONFIG_PATH is not set
+	DefaultConfigPath = ".gitlab-ci.yml"
+)
+
+// Environment the GitLab CI environment from which provenance is generated.
+//
+// PredicateVersion selects the SLSA provenance predicate to generate, defaults to intoto.PredicateVersionV02.
+type Environment struct {
+	Context          *Context `json:"gitlab,omitempty"`
+	PredicateVersion string   `json:"-"`
+}
+
+// Context holds the information about the job, retrieved from the GitLab CI predefined variables
+//
+// See https://docs.gitlab.com/ee/ci/variables/predefined_variables.html
+type Context struct {
+	ServerURL         string   `json:"server_url"`
+	ProjectURL        string   `json:"project_url"`
+	ProjectPath       string   `json:"project_path"`
+	CommitSHA         string   `json:"commit_sha"`
+	CommitRefName     string   `json:"commit_ref_name"`
+	CommitBranch      string   `json:"commit_branch"`
+	CommitTag         string   `json:"commit_tag"`
+	ConfigPath        string   `json:"config_path"`
+	PipelineID        string   `json:"pipeline_id"`
+	PipelineSource    string   `json:"pipeline_source"`
+	PipelineURL       string   `json:"pipeline_url"`
+	JobID             string   `json:"job_id"`
+	JobName           string   `json:"job_name"`
+	JobURL            string   `json:"job_url"`
+	RunnerID          string   `json:"runner_id"`
+	RunnerDescription string   `json:"runner_description"`
+	RunnerTags        []string `json:"runner_tags"`
+	RunnerVersion     string   `json:"runner_version"`
+	UserLogin         string   `json:"user_login"`
+}
+
+// IsGitLabCI reports whether the process runs in a GitLab CI job
+func IsGitLabCI(getenv func(string) string) bool {
+	return getenv("GITLAB_CI") == "true"
+}
+
+// ContextFromEnv reads the Context from the GitLab CI predefined variables using getenv, e.g. os.Getenv.
+// It fails when one of CI_PROJECT_URL, CI_COMMIT_SHA, CI_PIPELINE_ID or CI_JOB_ID is not set.
+func ContextFromEnv(getenv func(string) string) (*Context, error) {
+	var missing []string
+	required := func(key string) string {
+		v := getenv(key)
+		if v == "" {
+			missing = append(missing, key)
+		}
+		return v
+	}
+
+	c := &Context{
+		ServerURL:         getenv("CI_SERVER_URL"),
+		ProjectURL:        required("CI_PROJECT_URL"),
+		ProjectPath:       getenv("CI_PROJECT_PATH"),
+		CommitSHA:         required("CI_COMMIT_SHA"),
+		CommitRefName:     getenv("CI_COMMIT_REF_NAME"),
+		CommitBranch:      getenv("CI_COMMIT_BRANCH"),
+		CommitTag:         getenv("CI_COMMIT_TAG"),
+		ConfigPath:        getenv("CI_CONFIG_PATH"),
+		PipelineID:        required("CI_PIPELINE_ID"),
+		PipelineSource:    getenv("CI_PIPELINE_SOURCE"),
+		PipelineURL:       getenv("CI_PIPELINE_URL"),
+		JobID:             required("CI_JOB_ID"),
+		JobName:           getenv("CI_JOB_NAME"),
+		JobURL:            getenv("CI_JOB_URL"),
+		RunnerID:          getenv("CI_RUNNER_ID"),
+		RunnerDescription: getenv("CI_RUNNER_DESCRIPTION"),
+		RunnerTags:        parseRunnerTags(getenv("CI_RUNNER_TAGS")),
+		RunnerVersion:     getenv("CI_RUNNER_VERSION"),
+		UserLogin:         getenv("GITLAB_USER_LOGIN"),
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing GitLab CI variables: %s", strings.Join(missing, ", "))
+	}
+
+	if c.ConfigPath == "" {
+		c.ConfigPath = DefaultConfigPath
+	}
+	if c.JobURL == "" {
+		c.JobURL = fmt.Sprintf("%s/-/jobs/%s", c.ProjectURL, c.JobID)
+	}
+	return c, nil
+}
+
+// parseRunnerTags parses CI_RUNNER_TAGS, a JSON array since GitLab 14.x and a comma separated list before
+func parseRunnerTags(tags string) []string {
+	tags = strings.TrimSpace(tags)
+	if tags == "" {
+		return nil
+	}
+
+	var parsed []string
+	if err := json.Unmarshal([]byte(tags), &parsed); err == nil {
+		return parsed
+	}
+	for _, t := range strings.Split(tags, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			parsed = append(parsed, t)
+		}
+	}
+	return parsed
+}
+
+// Ref the git ref of the pipeline, e.g. refs/heads/main or refs/tags/v1.0.0
+func (c *Context) Ref() string {
+	switch {
+	case c.CommitTag != "":
+		return "refs/tags/" + c.CommitTag
+	case c.CommitBranch != "":
+		return "refs/heads/" + c.CommitBranch
+	default:
+		// NOTE: merge request pipelines only expose the ref name of the source branch.
+		return c.CommitRefName
+	}
+}
+
+func (c *Context) builderID() string {
+	if c.RunnerID == "" {
+		return c.ProjectURL + RunnerIDInfix + "unknown"
+	}
+	return c.ProjectURL + RunnerIDInfix + c.RunnerID
+}
+
+// PipelineParameters the externalParameters of the SLSA v1.0 GitLab CI buildType
+type PipelineParameters struct {
+	Pipeline Pipeline `json:"pipeline"`
+	Job      string   `json:"job,omitempty"`
+}
+
+// Pipeline identifies the pipeline configuration that was executed
+type Pipeline struct {
+	Ref        string `json:"ref"`
+	Repository string `json:"repository"`
+	Path       string `json:"path"`
+}
+
+// InternalParameters the internalParameters of the SLSA v1.0 GitLab CI buildType
+type InternalParameters struct {
+	GitLab InternalGitLabParameters `json:"gitlab"`
+}
+
+// InternalGitLabParameters holds the GitLab specific internalParameters
+type InternalGitLabParameters struct {
+	PipelineID        string   `json:"pipeline_id"`
+	PipelineSource    string   `json:"pipeline_source,omitempty"`
+	JobID             string   `json:"job_id"`
+	RunnerID          string   `json:"runner_id,omitempty"`
+	RunnerDescription string   `json:"runner_description,omitempty"`
+	RunnerTags        []string `json:"runner_tags,omitempty"`
+}
+
+// invocationEnvironment the SLSA v0.2 invocation environment, describing the runner executing the job
+type invocationEnvironment struct {
+	PipelineID        string   `json:"pipeline_id"`
+	JobID             string   `json:"job_id"`
+	JobName           string   `json:"job_name,omitempty"`
+	RunnerID          string   `json:"runner_id,omitempty"`
+	RunnerDescription string   `json:"runner_description,omitempty"`
+	RunnerTags        []string `json:"runner_tags,omitempty"`
+}
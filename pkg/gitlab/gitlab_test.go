@@ -0,0 +1,64 @@
+package gitlab_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/gitlab"
+)
+
+func envFunc(env map[string]string) func(string) string {
+	return func(key string) string {
+		return env[key]
+	}
+}
+
+func TestContextFromEnv(t *testing.T) {
+	assert := assert.New(t)
+
+	env := map[string]string{
+		"CI_SERVER_URL":         "https://gitlab.com",
+		"CI_PROJECT_URL":        "https://gitlab.com/philips-labs/slsa-provenance-action",
+		"CI_PROJECT_PATH":       "philips-labs/slsa-provenance-action",
+		"CI_COMMIT_SHA":         "c4f679f131dfb7f810fd411ac9475549d1c393df",
+		"CI_COMMIT_REF_NAME":    "v0.1.0",
+		"CI_COMMIT_TAG":         "v0.1.0",
+		"CI_PIPELINE_ID":        "1053418211",
+		"CI_JOB_ID":             "5052118342",
+		"CI_JOB_NAME":           "provenance",
+		"CI_RUNNER_ID":          "12270852",
+		"CI_RUNNER_DESCRIPTION": "3-blue.saas-linux-small-amd64.runners-manager.gitlab.com/default",
+		"CI_RUNNER_TAGS":        `["gce", "east-c", "linux", "saas-linux-small-amd64"]`,
+	}
+
+	c, err := gitlab.ContextFromEnv(envFunc(env))
+	assert.NoError(err)
+	assert.Equal("https://gitlab.com/philips-labs/slsa-provenance-action", c.ProjectURL)
+	assert.Equal("refs/tags/v0.1.0", c.Ref())
+	assert.Equal(gitlab.DefaultConfigPath, c.ConfigPath)
+	assert.Equal("https://gitlab.com/philips-labs/slsa-provenance-action/-/jobs/5052118342", c.JobURL)
+	assert.Equal([]string{"gce", "east-c", "linux", "saas-linux-small-amd64"}, c.RunnerTags)
+
+	env["CI_COMMIT_TAG"] = ""
+	env["CI_COMMIT_BRANCH"] = "main"
+	env["CI_CONFIG_PATH"] = "ci/build.yml"
+	env["CI_RUNNER_TAGS"] = "docker, linux"
+	c, err = gitlab.ContextFromEnv(envFunc(env))
+	assert.NoError(err)
+	assert.Equal("refs/heads/main", c.Ref())
+	assert.Equal("ci/build.yml", c.ConfigPath)
+	assert.Equal([]string{"docker", "linux"}, c.RunnerTags)
+
+	delete(env, "CI_COMMIT_SHA")
+	delete(env, "CI_JOB_ID")
+	_, err = gitlab.ContextFromEnv(envFunc(env))
+	assert.EqualError(err, "missing GitLab CI variables: CI_COMMIT_SHA, CI_JOB_ID")
+}
+
+func TestIsGitLabCI(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(gitlab.IsGitLabCI(envFunc(map[string]string{"GITLAB_CI": "true"})))
+	assert.False(gitlab.IsGitLabCI(envFunc(map[string]string{"GITHUB_ACTIONS": "true"})))
+}
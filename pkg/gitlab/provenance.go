@@ -0,0 +1,106 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+)
+
+// GenerateProvenanceStatement generates provenance from the GitLab CI job for the subjects of the subjecter
+func (e *Environment) GenerateProvenanceStatement(ctx context.Context, subjecter intoto.Subjecter, materials ...intoto.Item) (*intoto.Statement, error) {
+	subjects, err := subjecter.Subjects()
+	if err != nil {
+		return nil, err
+	}
+
+	if e.PredicateVersion == intoto.PredicateVersionV1 {
+		return e.provenanceStatementV1(subjects, materials)
+	}
+
+	environment, err := json.Marshal(invocationEnvironment{
+		PipelineID:        e.Context.PipelineID,
+		JobID:             e.Context.JobID,
+		JobName:           e.Context.JobName,
+		RunnerID:          e.Context.RunnerID,
+		RunnerDescription: e.Context.RunnerDescription,
+		RunnerTags:        e.Context.RunnerTags,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal invocation environment: %w", err)
+	}
+
+	stmt := intoto.SLSAProvenanceStatement(
+		intoto.WithSubject(subjects),
+		intoto.WithBuilder(e.Context.builderID()),
+		// NOTE: Retried jobs get a new job ID, the job URL uniquely identifies the invocation.
+		intoto.WithMetadata(e.Context.JobURL),
+		intoto.WithInvocation(
+			BuildType,
+			e.Context.ConfigPath,
+			environment,
+			nil,
+			[]intoto.Item{
+				{URI: intoto.SourceURI(e.Context.ProjectURL, e.Context.Ref()), Digest: intoto.DigestSet{"sha1": e.Context.CommitSHA}},
+			},
+		),
+		intoto.WithMaterials(materials),
+	)
+
+	return stmt, nil
+}
+
+func (e *Environment) provenanceStatementV1(subjects []intoto.Subject, materials []intoto.Item) (*intoto.Statement, error) {
+	externalParameters, err := json.Marshal(PipelineParameters{
+		Pipeline: Pipeline{
+			Ref:        e.Context.Ref(),
+			Repository: e.Context.ProjectURL,
+			Path:       e.Context.ConfigPath,
+		},
+		Job: e.Context.JobName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal external parameters: %w", err)
+	}
+
+	internalParameters, err := json.Marshal(InternalParameters{
+		GitLab: InternalGitLabParameters{
+			PipelineID:        e.Context.PipelineID,
+			PipelineSource:    e.Context.PipelineSource,
+			JobID:             e.Context.JobID,
+			RunnerID:          e.Context.RunnerID,
+			RunnerDescription: e.Context.RunnerDescription,
+			RunnerTags:        e.Context.RunnerTags,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal internal parameters: %w", err)
+	}
+
+	stmt := intoto.SLSAProvenanceStatementV1(
+		intoto.WithSubject(subjects),
+		intoto.WithRunDetails(e.Context.builderID(), e.Context.JobURL),
+		intoto.WithBuildDefinition(
+			BuildTypeV1,
+			externalParameters,
+			internalParameters,
+			[]intoto.ResourceDescriptor{
+				{URI: intoto.SourceURI(e.Context.ProjectURL, e.Context.Ref()), Digest: intoto.DigestSet{"gitCommit": e.Context.CommitSHA}},
+			},
+		),
+		intoto.WithMaterials(materials),
+	)
+
+	return stmt, nil
+}
+
+// PersistProvenanceStatement writes the provenance statement at the given path
+func (e *Environment) PersistProvenanceStatement(ctx context.Context, stmt *intoto.Statement, path string) error {
+	return intoto.WriteProvenance(stmt, path)
+}
+
+// PersistProvenanceEnvelope writes the DSSE envelope wrapping the provenance statement at the given path
+func (e *Environment) PersistProvenanceEnvelope(ctx context.Context, env *intoto.Envelope, path string) error {
+	return intoto.WriteProvenance(env, path)
+}
@@ -0,0 +1,85 @@
+package gitlab_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/gitlab"
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+)
+
+func gitlabContext() *gitlab.Context {
+	return &gitlab.Context{
+		ProjectURL:        "https://gitlab.com/philips-labs/slsa-provenance-action",
+		CommitSHA:         "849fb987efc0c0fc72e26a38f63f0c00225132be",
+		CommitBranch:      "main",
+		ConfigPath:        gitlab.DefaultConfigPath,
+		PipelineID:        "1053418211",
+		PipelineSource:    "push",
+		JobID:             "5052118342",
+		JobName:           "provenance",
+		JobURL:            "https://gitlab.com/philips-labs/slsa-provenance-action/-/jobs/5052118342",
+		RunnerID:          "12270852",
+		RunnerDescription: "shared runner",
+		RunnerTags:        []string{"docker", "linux"},
+	}
+}
+
+func artifactSubjecter(t *testing.T) intoto.Subjecter {
+	artifact := path.Join(t.TempDir(), "salsa.txt")
+	if err := os.WriteFile(artifact, []byte("salsa"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return intoto.NewFilePathSubjecter(artifact)
+}
+
+func TestGenerateProvenance(t *testing.T) {
+	assert := assert.New(t)
+
+	env := gitlab.Environment{Context: gitlabContext()}
+	stmt, err := env.GenerateProvenanceStatement(context.Background(), artifactSubjecter(t))
+	if !assert.NoError(err) {
+		return
+	}
+
+	assert.Equal(intoto.SlsaPredicateType, stmt.PredicateType)
+	assert.Len(stmt.Subject, 1)
+	assert.Equal("https://gitlab.com/philips-labs/slsa-provenance-action/-/runners/12270852", stmt.Predicate.Builder.ID)
+	assert.Equal(gitlab.BuildType, stmt.Predicate.BuildType)
+	assert.Equal("https://gitlab.com/philips-labs/slsa-provenance-action/-/jobs/5052118342", stmt.Predicate.Metadata.BuildInvocationID)
+	assert.Equal(".gitlab-ci.yml", stmt.Predicate.Invocation.ConfigSource.EntryPoint)
+	assert.Equal("git+https://gitlab.com/philips-labs/slsa-provenance-action@refs/heads/main", stmt.Predicate.Invocation.ConfigSource.URI)
+	assert.Equal(intoto.DigestSet{"sha1": "849fb987efc0c0fc72e26a38f63f0c00225132be"}, stmt.Predicate.Invocation.ConfigSource.Digest)
+	assert.JSONEq(`{"pipeline_id":"1053418211","job_id":"5052118342","job_name":"provenance","runner_id":"12270852","runner_description":"shared runner","runner_tags":["docker","linux"]}`, string(stmt.Predicate.Invocation.Environment))
+	assert.Len(stmt.Predicate.Materials, 1)
+}
+
+func TestGenerateProvenanceV1(t *testing.T) {
+	assert := assert.New(t)
+
+	env := gitlab.Environment{Context: gitlabContext(), PredicateVersion: intoto.PredicateVersionV1}
+	stmt, err := env.GenerateProvenanceStatement(context.Background(), artifactSubjecter(t))
+	if !assert.NoError(err) {
+		return
+	}
+
+	p := stmt.PredicateV1
+	assert.Equal(intoto.SlsaPredicateTypeV1, stmt.PredicateType)
+	assert.Equal("https://gitlab.com/philips-labs/slsa-provenance-action/-/runners/12270852", p.RunDetails.Builder.ID)
+	assert.Equal("https://gitlab.com/philips-labs/slsa-provenance-action/-/jobs/5052118342", p.RunDetails.Metadata.InvocationID)
+	assert.Equal(gitlab.BuildTypeV1, p.BuildDefinition.BuildType)
+	assert.JSONEq(`{"pipeline":{"ref":"refs/heads/main","repository":"https://gitlab.com/philips-labs/slsa-provenance-action","path":".gitlab-ci.yml"},"job":"provenance"}`, string(p.BuildDefinition.ExternalParameters))
+
+	var internal gitlab.InternalParameters
+	assert.NoError(json.Unmarshal(p.BuildDefinition.InternalParameters, &internal))
+	assert.Equal("1053418211", internal.GitLab.PipelineID)
+	assert.Equal([]string{"docker", "linux"}, internal.GitLab.RunnerTags)
+	assert.Equal([]intoto.ResourceDescriptor{
+		{URI: "git+https://gitlab.com/philips-labs/slsa-provenance-action@refs/heads/main", Digest: intoto.DigestSet{"gitCommit": "849fb987efc0c0fc72e26a38f63f0c00225132be"}},
+	}, p.BuildDefinition.ResolvedDependencies)
+}
@@ -0,0 +1,260 @@
+package intoto
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/digest"
+)
+
+// ArchiveMemberSeparator separates the archive name from the member name in the subject name, e.g. release.tar.gz!/bin/app
+const ArchiveMemberSeparator = "!/"
+
+// ErrArchiveLimitExceeded is returned when an archive exceeds the ArchiveLimits, e.g. a zip bomb
+var ErrArchiveLimitExceeded = errors.New("archive exceeds limits")
+
+// ArchiveLimits guards against decompression bombs when hashing archive members
+type ArchiveLimits struct {
+	// MaxMembers the maximum number of members in a single archive
+	MaxMembers int
+	// MaxSize the maximum total uncompressed size of a single archive in bytes
+	MaxSize int64
+	// MaxRatio the maximum ratio between the uncompressed and compressed size of a single archive
+	MaxRatio int64
+}
+
+// DefaultArchiveLimits the limits used when expanding archive members
+var DefaultArchiveLimits = ArchiveLimits{
+	MaxMembers: 100000,
+	MaxSize:    8 << 30,
+	MaxRatio:   1000,
+}
+
+// minRatioCheckSize the compression ratio is only enforced once this many bytes are decompressed,
+// as tiny archives easily reach high ratios.
+const minRatioCheckSize = 1 << 20
+
+type archiveFormat int
+
+const (
+	notAnArchive archiveFormat = iota
+	tarArchive
+	tarGzArchive
+	tarZstdArchive
+	zipArchive
+)
+
+func archiveFormatOf(name string) archiveFormat {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(name, ".tar"):
+		return tarArchive
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return tarGzArchive
+	case strings.HasSuffix(name, ".tar.zst"):
+		return tarZstdArchive
+	case strings.HasSuffix(name, ".zip"):
+		return zipArchive
+	default:
+		return notAnArchive
+	}
+}
+
+// archiveMembers hashes the regular file members of the archive at abspath. The subjects are named
+// using the archive name and the member path, e.g. release.tar.gz!/bin/app and sorted by member path.
+func archiveMembers(abspath, name string, algorithms []string, limits ArchiveLimits) ([]Subject, error) {
+	var (
+		members []Subject
+		err     error
+	)
+	switch archiveFormatOf(name) {
+	case tarArchive, tarGzArchive, tarZstdArchive:
+		members, err = tarMembers(abspath, algorithms, limits)
+	case zipArchive:
+		members, err = zipMembers(abspath, algorithms, limits)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash members of archive %s: %w", name, err)
+	}
+
+	sort.Slice(members, func(i, j int) bool { return members[i].Name < members[j].Name })
+	for i := range members {
+		if i > 0 && members[i].Name == members[i-1].Name {
+			return nil, fmt.Errorf("failed to hash members of archive %s: duplicate member %s", name, members[i].Name)
+		}
+	}
+	for i := range members {
+		members[i].Name = name + ArchiveMemberSeparator + members[i].Name
+	}
+	return members, nil
+}
+
+func tarMembers(abspath string, algorithms []string, limits ArchiveLimits) ([]Subject, error) {
+	file, err := os.Open(abspath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	var r io.Reader = file
+	switch archiveFormatOf(abspath) {
+	case tarGzArchive:
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	case tarZstdArchive:
+		zr, err := zstd.NewReader(file)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	bounded := &boundedReader{r: r, limits: limits, compressedSize: info.Size()}
+	tr := tar.NewReader(bounded)
+
+	var members []Subject
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return members, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !hdr.FileInfo().Mode().IsRegular() {
+			continue
+		}
+
+		name, err := memberName(hdr.Name)
+		if err != nil {
+			return nil, err
+		}
+		if len(members) >= limits.MaxMembers {
+			return nil, fmt.Errorf("%w: more than %d members", ErrArchiveLimitExceeded, limits.MaxMembers)
+		}
+
+		digests, err := digest.FromReader(tr, hdr.Size, algorithms...)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, Subject{Name: name, Digest: DigestSet(digests)})
+	}
+}
+
+func zipMembers(abspath string, algorithms []string, limits ArchiveLimits) ([]Subject, error) {
+	zr, err := zip.OpenReader(abspath)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	info, err := os.Stat(abspath)
+	if err != nil {
+		return nil, err
+	}
+
+	bounded := &boundedReader{limits: limits, compressedSize: info.Size()}
+
+	var members []Subject
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+
+		name, err := memberName(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		if len(members) >= limits.MaxMembers {
+			return nil, fmt.Errorf("%w: more than %d members", ErrArchiveLimitExceeded, limits.MaxMembers)
+		}
+		// NOTE: check the declared size upfront, the boundedReader guards against headers lying about the size.
+		if f.UncompressedSize64 > uint64(limits.MaxSize-bounded.read) {
+			return nil, fmt.Errorf("%w: uncompressed size exceeds %d bytes", ErrArchiveLimitExceeded, limits.MaxSize)
+		}
+
+		digests, err := zipMemberDigests(f, bounded, algorithms)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, Subject{Name: name, Digest: DigestSet(digests)})
+	}
+
+	return members, nil
+}
+
+func zipMemberDigests(f *zip.File, bounded *boundedReader, algorithms []string) (map[string]string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	bounded.r = rc
+	return digest.FromReader(bounded, int64(f.UncompressedSize64), algorithms...)
+}
+
+// memberName cleans the member path and rejects absolute paths and paths escaping the archive root
+func memberName(name string) (string, error) {
+	cleaned, ok := cleanRelativePath(name)
+	if !ok {
+		return "", fmt.Errorf("unsafe member path %q", name)
+	}
+	return cleaned, nil
+}
+
+// cleanRelativePath cleans the slash separated path, it reports false for absolute paths and paths escaping the root
+func cleanRelativePath(name string) (string, bool) {
+	cleaned := path.Clean(strings.ReplaceAll(name, `\`, "/"))
+	hasVolume := len(cleaned) >= 2 && cleaned[1] == ':'
+	if path.IsAbs(cleaned) || hasVolume || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", false
+	}
+	return strings.TrimPrefix(cleaned, "./"), true
+}
+
+// boundedReader fails once more than the maximum size is decompressed, or when the compression ratio is exceeded
+type boundedReader struct {
+	r              io.Reader
+	limits         ArchiveLimits
+	compressedSize int64
+	read           int64
+}
+
+func (b *boundedReader) Read(p []byte) (int, error) {
+	if remaining := b.limits.MaxSize - b.read + 1; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := b.r.Read(p)
+	b.read += int64(n)
+
+	if b.read > b.limits.MaxSize {
+		return n, fmt.Errorf("%w: uncompressed size exceeds %d bytes", ErrArchiveLimitExceeded, b.limits.MaxSize)
+	}
+	if b.read > minRatioCheckSize && b.compressedSize > 0 && b.read/b.compressedSize > b.limits.MaxRatio {
+		return n, fmt.Errorf("%w: compression ratio exceeds %d", ErrArchiveLimitExceeded, b.limits.MaxRatio)
+	}
+	return n, err
+}
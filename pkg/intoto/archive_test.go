@@ -0,0 +1,185 @@
+package intoto
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/digest"
+)
+
+type archiveMember struct {
+	name    string
+	content []byte
+	link    bool
+}
+
+var releaseMembers = []archiveMember{
+	{name: "bin/app", content: []byte("app binary\n")},
+	{name: "./README.md", content: []byte("# app\n")},
+	{name: "bin/app-link", link: true},
+}
+
+func writeTar(t *testing.T, w io.Writer, members []archiveMember) {
+	tw := tar.NewWriter(w)
+	for _, m := range members {
+		hdr := &tar.Header{Name: m.name, Mode: 0644, Size: int64(len(m.content)), Typeflag: tar.TypeReg}
+		if m.link {
+			hdr = &tar.Header{Name: m.name, Linkname: "app", Typeflag: tar.TypeSymlink}
+		}
+		assert.NoError(t, tw.WriteHeader(hdr))
+		_, err := tw.Write(m.content)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+}
+
+func createArchive(t *testing.T, dir, name string, members []archiveMember) string {
+	var buf bytes.Buffer
+	switch archiveFormatOf(name) {
+	case tarArchive:
+		writeTar(t, &buf, members)
+	case tarGzArchive:
+		gz := gzip.NewWriter(&buf)
+		writeTar(t, gz, members)
+		assert.NoError(t, gz.Close())
+	case tarZstdArchive:
+		zw, err := zstd.NewWriter(&buf)
+		assert.NoError(t, err)
+		writeTar(t, zw, members)
+		assert.NoError(t, zw.Close())
+	case zipArchive:
+		zw := zip.NewWriter(&buf)
+		for _, m := range members {
+			if m.link {
+				continue
+			}
+			w, err := zw.Create(m.name)
+			assert.NoError(t, err)
+			_, err = w.Write(m.content)
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, zw.Close())
+	}
+
+	p := path.Join(dir, name)
+	assert.NoError(t, os.WriteFile(p, buf.Bytes(), 0644))
+	return p
+}
+
+func TestArchiveMembers(t *testing.T) {
+	appDigest := digest.SHA256Hex([]byte("app binary\n"))
+	readmeDigest := digest.SHA256Hex([]byte("# app\n"))
+
+	for _, name := range []string{"release.tar", "release.tar.gz", "release.tgz", "release.tar.zst", "release.zip"} {
+		t.Run(name, func(tt *testing.T) {
+			assert := assert.New(tt)
+
+			root := tt.TempDir()
+			archive := createArchive(tt, root, name, releaseMembers)
+			assert.NoError(os.WriteFile(path.Join(root, "notes.txt"), []byte("notes"), 0644))
+
+			s, err := NewFilePathSubjecter(root, WithArchiveMembers(ArchiveLimits{})).Subjects()
+			if !assert.NoError(err) {
+				return
+			}
+
+			content, err := os.ReadFile(archive)
+			assert.NoError(err)
+			assert.Equal([]Subject{
+				{Name: "notes.txt", Digest: DigestSet{"sha256": digest.SHA256Hex([]byte("notes"))}},
+				{Name: name, Digest: DigestSet{"sha256": digest.SHA256Hex(content)}},
+				{Name: name + "!/README.md", Digest: DigestSet{"sha256": readmeDigest}},
+				{Name: name + "!/bin/app", Digest: DigestSet{"sha256": appDigest}},
+			}, s)
+
+			s, err = NewFilePathSubjecter(root).Subjects()
+			assert.NoError(err)
+			assert.Len(s, 2)
+		})
+	}
+}
+
+func TestArchiveMembersUnsafePaths(t *testing.T) {
+	for _, member := range []string{"../evil", "bin/../../evil", "/etc/passwd", `..\evil`, "C:/evil"} {
+		for _, name := range []string{"release.tar.gz", "release.zip"} {
+			t.Run(name+" "+member, func(tt *testing.T) {
+				assert := assert.New(tt)
+
+				root := tt.TempDir()
+				createArchive(tt, root, name, []archiveMember{{name: member, content: []byte("evil")}})
+
+				_, err := NewFilePathSubjecter(root, WithArchiveMembers(DefaultArchiveLimits)).Subjects()
+				assert.EqualError(err, fmt.Sprintf("failed to hash members of archive %s: unsafe member path %q", name, member))
+			})
+		}
+	}
+}
+
+func TestArchiveMembersLimits(t *testing.T) {
+	zeros := bytes.Repeat([]byte{0}, 4<<20)
+
+	testCases := []struct {
+		name    string
+		members []archiveMember
+		limits  ArchiveLimits
+		err     string
+	}{
+		{
+			name:    "release.zip",
+			members: []archiveMember{{name: "zeros", content: zeros}},
+			limits:  ArchiveLimits{MaxRatio: 100},
+			err:     "failed to hash members of archive release.zip: archive exceeds limits: compression ratio exceeds 100",
+		},
+		{
+			name:    "release.tar.gz",
+			members: []archiveMember{{name: "zeros", content: zeros}},
+			limits:  ArchiveLimits{MaxRatio: 100},
+			err:     "failed to hash members of archive release.tar.gz: archive exceeds limits: compression ratio exceeds 100",
+		},
+		{
+			name:    "release.zip",
+			members: []archiveMember{{name: "zeros", content: zeros}},
+			limits:  ArchiveLimits{MaxSize: 1 << 20},
+			err:     "failed to hash members of archive release.zip: archive exceeds limits: uncompressed size exceeds 1048576 bytes",
+		},
+		{
+			name:    "release.tar.zst",
+			members: []archiveMember{{name: "zeros", content: zeros}},
+			limits:  ArchiveLimits{MaxSize: 1 << 20},
+			err:     "failed to hash members of archive release.tar.zst: archive exceeds limits: uncompressed size exceeds 1048576 bytes",
+		},
+		{
+			name:    "release.tar",
+			members: releaseMembers,
+			limits:  ArchiveLimits{MaxMembers: 1},
+			err:     "failed to hash members of archive release.tar: archive exceeds limits: more than 1 members",
+		},
+		{
+			name:    "release.tar",
+			members: []archiveMember{{name: "bin/app", content: []byte("a")}, {name: "./bin/app", content: []byte("b")}},
+			err:     "failed to hash members of archive release.tar: duplicate member bin/app",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.err, func(tt *testing.T) {
+			assert := assert.New(tt)
+
+			root := tt.TempDir()
+			createArchive(tt, root, tc.name, tc.members)
+
+			_, err := NewFilePathSubjecter(root, WithArchiveMembers(tc.limits)).Subjects()
+			assert.EqualError(err, tc.err)
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package intoto
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/digest"
+)
+
+var (
+	// bsdChecksumLine matches the BSD (tagged) format, e.g. SHA256 (file.tar.gz) = abc...
+	bsdChecksumLine = regexp.MustCompile(`^([A-Za-z0-9-]+) \((.+)\) = ([0-9a-fA-F]+)$`)
+	// gnuChecksumLine matches the GNU coreutils format, e.g. abc...  file.tar.gz or abc... *file.tar.gz
+	gnuChecksumLine = regexp.MustCompile(`^\\?([0-9a-fA-F]+) [ *](.+)$`)
+
+	bsdAlgorithms = map[string]string{
+		"SHA1":        digest.SHA1,
+		"SHA256":      digest.SHA256,
+		"SHA384":      digest.SHA384,
+		"SHA512":      digest.SHA512,
+		"BLAKE2b-256": digest.Blake2b256,
+	}
+
+	gnuAlgorithms = map[int]string{
+		40:  digest.SHA1,
+		64:  digest.SHA256,
+		96:  digest.SHA384,
+		128: digest.SHA512,
+	}
+)
+
+// ChecksumsSubjecterOption option flag to configure the ChecksumsSubjecter
+type ChecksumsSubjecterOption func(*ChecksumsSubjecter)
+
+// WithChecksumAlgorithm sets the digest algorithm of GNU formatted checksums, instead of deriving it from the checksum length
+func WithChecksumAlgorithm(algorithm string) ChecksumsSubjecterOption {
+	return func(c *ChecksumsSubjecter) {
+		c.algorithm = algorithm
+	}
+}
+
+// WithVerifyArtifacts re-hashes the artifacts in root and fails when any of the checksums doesn't match.
+// When root is empty the artifacts are resolved relative to the checksums file.
+func WithVerifyArtifacts(root string) ChecksumsSubjecterOption {
+	return func(c *ChecksumsSubjecter) {
+		c.verify = true
+		c.root = root
+	}
+}
+
+// ChecksumsSubjecter implements Subjector to retrieve Subject from checksums files in GNU coreutils or BSD format
+type ChecksumsSubjecter struct {
+	paths     []string
+	algorithm string
+	verify    bool
+	root      string
+}
+
+// NewChecksumsSubjecter parses the checksums files (e.g. SHA256SUMS) into subjects, without reading the artifacts.
+//
+// Checksums of the same artifact in multiple files are merged into a single subject.
+func NewChecksumsSubjecter(paths []string, opts ...ChecksumsSubjecterOption) *ChecksumsSubjecter {
+	c := &ChecksumsSubjecter{paths: paths}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Subjects parses the checksums files and optionally verifies the checksums against the artifacts on disk.
+func (c *ChecksumsSubjecter) Subjects() ([]Subject, error) {
+	if c.algorithm != "" {
+		if err := digest.Validate(c.algorithm); err != nil {
+			return nil, err
+		}
+	}
+
+	subjects := make(map[string]DigestSet)
+	roots := make(map[string]string)
+	for _, path := range c.paths {
+		if err := c.parse(path, subjects); err != nil {
+			return nil, err
+		}
+		for name := range subjects {
+			if _, ok := roots[name]; !ok {
+				roots[name] = filepath.Dir(path)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(subjects))
+	for name := range subjects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	s := make([]Subject, len(names))
+	for i, name := range names {
+		s[i] = Subject{Name: name, Digest: subjects[name]}
+	}
+
+	if c.verify {
+		if err := c.verifyArtifacts(s, roots); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func (c *ChecksumsSubjecter) parse(path string, subjects map[string]DigestSet) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to read checksums file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for lineNr := 1; scanner.Scan(); lineNr++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, alg, value, err := c.parseLine(line)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", path, lineNr, err)
+		}
+		name = strings.TrimPrefix(name, "./")
+
+		digests, ok := subjects[name]
+		if !ok {
+			digests = DigestSet{}
+			subjects[name] = digests
+		}
+		if existing, ok := digests[alg]; ok && existing != value {
+			return fmt.Errorf("%s:%d: conflicting %s checksum for %s", path, lineNr, alg, name)
+		}
+		digests[alg] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read checksums file: %w", err)
+	}
+
+	return nil
+}
+
+func (c *ChecksumsSubjecter) parseLine(line string) (name, alg, value string, err error) {
+	if m := bsdChecksumLine.FindStringSubmatch(line); m != nil {
+		alg, ok := bsdAlgorithms[m[1]]
+		if !ok {
+			return "", "", "", fmt.Errorf("unsupported checksum algorithm %q", m[1])
+		}
+		return m[2], alg, strings.ToLower(m[3]), nil
+	}
+
+	if m := gnuChecksumLine.FindStringSubmatch(line); m != nil {
+		name = m[2]
+		// NOTE: GNU coreutils prefixes the line with a backslash when the file name contains a backslash or newline.
+		if strings.HasPrefix(line, `\`) {
+			name = strings.NewReplacer(`\\`, `\`, `\n`, "\n").Replace(name)
+		}
+
+		alg := c.algorithm
+		if alg == "" {
+			var ok bool
+			if alg, ok = gnuAlgorithms[len(m[1])]; !ok {
+				return "", "", "", fmt.Errorf("unable to derive the checksum algorithm from a checksum of %d characters", len(m[1]))
+			}
+		}
+		return name, alg, strings.ToLower(m[1]), nil
+	}
+
+	return "", "", "", errors.New("invalid checksum line, expected GNU coreutils or BSD format")
+}
+
+// verifyArtifacts re-hashes all artifacts and reports all mismatches at once
+func (c *ChecksumsSubjecter) verifyArtifacts(subjects []Subject, roots map[string]string) error {
+	var mismatches []string
+	for _, s := range subjects {
+		root := c.root
+		if root == "" {
+			root = roots[s.Name]
+		}
+
+		algs := make([]string, 0, len(s.Digest))
+		for alg := range s.Digest {
+			algs = append(algs, alg)
+		}
+		sort.Strings(algs)
+
+		// NOTE: the names come from the checksums file, never hash files outside of the artifact root.
+		name, ok := cleanRelativePath(s.Name)
+		if !ok {
+			mismatches = append(mismatches, fmt.Sprintf("unsafe artifact path %q", s.Name))
+			continue
+		}
+
+		actual, err := digest.FromFile(filepath.Join(root, filepath.FromSlash(name)), algs...)
+		if err != nil {
+			mismatches = append(mismatches, err.Error())
+			continue
+		}
+		for _, alg := range algs {
+			if actual[alg] != s.Digest[alg] {
+				mismatches = append(mismatches, fmt.Sprintf("%s checksum mismatch for %s, expected %s, got %s", alg, s.Name, s.Digest[alg], actual[alg]))
+			}
+		}
+	}
+
+	if len(mismatches) > 0 {
+		return fmt.Errorf("checksums verification failed: %s", strings.Join(mismatches, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package intoto
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/digest"
+)
+
+func checksumsDir() string {
+	_, filename, _, _ := runtime.Caller(0)
+	return path.Join(path.Dir(filename), "../../test-data/checksums")
+}
+
+func TestChecksumsSubjecter(t *testing.T) {
+	assert := assert.New(t)
+
+	dist := path.Join(checksumsDir(), "dist")
+	appSHA256 := "efe830daafaf34656a6a130086057015f3e66cee6bd3c8de8c0f488bf2a8a827"
+	appSHA512 := "0b134d18c10063631fb24b1aa9c1fe1a911800b0a7309e56dd0e21e92d5a2413ba9ead6cfb251faa5260ecc02a39f51f0a5cb671aa6069b750450a405b076e8d"
+
+	s, err := NewChecksumsSubjecter([]string{path.Join(dist, "SHA256SUMS")}).Subjects()
+	assert.NoError(err)
+	if assert.Len(s, 3) {
+		assert.Equal(Subject{Name: "app", Digest: DigestSet{digest.SHA256: appSHA256}}, s[0])
+		assert.Equal("app.tar.gz", s[1].Name)
+		assert.Equal("docs/README.md", s[2].Name)
+	}
+
+	s, err = NewChecksumsSubjecter([]string{path.Join(dist, "SHA256SUMS"), path.Join(dist, "SHA512SUMS.bsd")}, WithVerifyArtifacts("")).Subjects()
+	assert.NoError(err)
+	if assert.Len(s, 3) {
+		assert.Equal(Subject{Name: "app", Digest: DigestSet{digest.SHA256: appSHA256, digest.SHA512: appSHA512}}, s[0])
+		assert.Len(s[1].Digest, 2)
+		assert.Len(s[2].Digest, 1)
+	}
+}
+
+func TestChecksumsSubjecterVerifyMismatch(t *testing.T) {
+	assert := assert.New(t)
+
+	tampered := path.Join(checksumsDir(), "SHA256SUMS.tampered")
+
+	s, err := NewChecksumsSubjecter([]string{tampered}).Subjects()
+	assert.NoError(err)
+	assert.Len(s, 1)
+
+	_, err = NewChecksumsSubjecter([]string{tampered}, WithVerifyArtifacts(path.Join(checksumsDir(), "dist"))).Subjects()
+	assert.EqualError(err, "checksums verification failed: sha256 checksum mismatch for app, expected 0fe830daafaf34656a6a130086057015f3e66cee6bd3c8de8c0f488bf2a8a827, got efe830daafaf34656a6a130086057015f3e66cee6bd3c8de8c0f488bf2a8a827")
+
+	_, err = NewChecksumsSubjecter([]string{tampered}, WithVerifyArtifacts("")).Subjects()
+	assert.EqualError(err, "checksums verification failed: open "+path.Join(checksumsDir(), "app")+": no such file or directory")
+}
+
+func TestChecksumsSubjecterVerifyUnsafePaths(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	root := path.Join(dir, "dist")
+	assert.NoError(os.Mkdir(root, 0755))
+	secret := path.Join(dir, "secret")
+	assert.NoError(os.WriteFile(secret, []byte("secret"), 0644))
+
+	sum := digest.SHA256Hex([]byte("secret"))
+	checksums := path.Join(dir, "SHA256SUMS")
+	assert.NoError(os.WriteFile(checksums, []byte(fmt.Sprintf("%s  ../secret\n%s  %s\n%s  app/../../secret\n", sum, sum, secret, sum)), 0644))
+
+	_, err := NewChecksumsSubjecter([]string{checksums}, WithVerifyArtifacts(root)).Subjects()
+	assert.EqualError(err, fmt.Sprintf(`checksums verification failed: unsafe artifact path "../secret"; unsafe artifact path %q; unsafe artifact path "app/../../secret"`, secret))
+}
+
+func TestChecksumsSubjecterFormats(t *testing.T) {
+	sha1 := "22596363b3de40b06f981fb85d82312e8c0ed511"
+	sha256 := "a948904f2f0f479b8f8197694b30184b0d2ed1c1cd2a1ec0fb85d299a192a447"
+
+	testCases := []struct {
+		name     string
+		content  string
+		opts     []ChecksumsSubjecterOption
+		expected []Subject
+		err      string
+	}{
+		{
+			name:     "gnu text mode",
+			content:  sha256 + "  hello.txt\n",
+			expected: []Subject{{Name: "hello.txt", Digest: DigestSet{digest.SHA256: sha256}}},
+		},
+		{
+			name:     "gnu binary mode and relative path",
+			content:  sha256 + " *./dist/hello world.txt\r\n",
+			expected: []Subject{{Name: "dist/hello world.txt", Digest: DigestSet{digest.SHA256: sha256}}},
+		},
+		{
+			name:     "gnu escaped file name",
+			content:  `\` + sha256 + `  hello\\world\n.txt` + "\n",
+			expected: []Subject{{Name: "hello\\world\n.txt", Digest: DigestSet{digest.SHA256: sha256}}},
+		},
+		{
+			name:     "gnu sha1 derived from length",
+			content:  sha1 + "  hello.txt\n",
+			expected: []Subject{{Name: "hello.txt", Digest: DigestSet{digest.SHA1: sha1}}},
+		},
+		{
+			name:     "gnu explicit algorithm",
+			content:  sha256 + "  hello.txt\n",
+			opts:     []ChecksumsSubjecterOption{WithChecksumAlgorithm(digest.Blake2b256)},
+			expected: []Subject{{Name: "hello.txt", Digest: DigestSet{digest.Blake2b256: sha256}}},
+		},
+		{
+			name:     "bsd",
+			content:  "SHA1 (hello.txt) = " + sha1 + "\nSHA256 (hello.txt) = " + sha256 + "\n",
+			expected: []Subject{{Name: "hello.txt", Digest: DigestSet{digest.SHA1: sha1, digest.SHA256: sha256}}},
+		},
+		{
+			name:    "bsd unsupported algorithm",
+			content: "MD5 (hello.txt) = 6f5902ac237024bdd0c176cb93063dc4\n",
+			err:     `checksums:1: unsupported checksum algorithm "MD5"`,
+		},
+		{
+			name:    "gnu unknown length",
+			content: "\n6f5902ac237024bdd0c176cb93063dc4  hello.txt\n",
+			err:     "checksums:2: unable to derive the checksum algorithm from a checksum of 32 characters",
+		},
+		{
+			name:    "conflicting checksums",
+			content: sha256 + "  hello.txt\n" + sha256[1:] + "0  hello.txt\n",
+			err:     "checksums:2: conflicting sha256 checksum for hello.txt",
+		},
+		{
+			name:    "invalid line",
+			content: "hello.txt\n",
+			err:     "checksums:1: invalid checksum line, expected GNU coreutils or BSD format",
+		},
+		{
+			name:    "unsupported explicit algorithm",
+			content: sha256 + "  hello.txt\n",
+			opts:    []ChecksumsSubjecterOption{WithChecksumAlgorithm("md5")},
+			err:     `unsupported digest algorithm "md5"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			assert := assert.New(tt)
+
+			file := path.Join(tt.TempDir(), "checksums")
+			assert.NoError(os.WriteFile(file, []byte(tc.content), 0644))
+
+			s, err := NewChecksumsSubjecter([]string{file}, tc.opts...).Subjects()
+			if tc.err != "" {
+				if assert.Error(err) {
+					assert.Contains(err.Error(), tc.err)
+				}
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(tc.expected, s)
+		})
+	}
+
+	_, err := NewChecksumsSubjecter([]string{"non-existing"}).Subjects()
+	assert.EqualError(t, err, "failed to read checksums file: open non-existing: no such file or directory")
+}
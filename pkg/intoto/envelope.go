@@ -0,0 +1,85 @@
+package intoto
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// PayloadType the DSSE payloadType used for in-toto statements
+	// See: https://github.com/in-toto/attestation/blob/main/spec/v1/envelope.md
+	PayloadType = "application/vnd.in-toto+json"
+)
+
+// Envelope wraps an in-toto statement to be able to attach signatures to the Statement
+//
+// See: https://github.com/secure-systems-lab/dsse/blob/master/envelope.md
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature a signature over the PAE of the Envelope payload
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// NewEnvelope wraps the base64 encoded Statement in an Envelope without any signatures
+func NewEnvelope(stmt *Statement) (*Envelope, error) {
+	payload, err := json.Marshal(stmt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal statement: %w", err)
+	}
+
+	return &Envelope{
+		PayloadType: PayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []Signature{},
+	}, nil
+}
+
+// DecodePayload returns the base64 decoded payload
+func (e *Envelope) DecodePayload() ([]byte, error) {
+	payload, err := base64.StdEncoding.DecodeString(e.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode envelope payload: %w", err)
+	}
+	return payload, nil
+}
+
+// Statement returns the Statement wrapped in the Envelope
+func (e *Envelope) Statement() (*Statement, error) {
+	if e.PayloadType != PayloadType {
+		return nil, fmt.Errorf("unsupported payloadType %q, expected %q", e.PayloadType, PayloadType)
+	}
+
+	payload, err := e.DecodePayload()
+	if err != nil {
+		return nil, err
+	}
+
+	var stmt Statement
+	if err := json.Unmarshal(payload, &stmt); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal envelope statement: %w", err)
+	}
+	return &stmt, nil
+}
+
+// PAE returns the pre-authentication encoding of the Envelope payload, which is the message to be signed
+func (e *Envelope) PAE() ([]byte, error) {
+	payload, err := e.DecodePayload()
+	if err != nil {
+		return nil, err
+	}
+	return PAE(e.PayloadType, payload), nil
+}
+
+// PAE computes the DSSE v1 pre-authentication encoding
+//
+//	PAE(type, body) = "DSSEv1" + SP + LEN(type) + SP + type + SP + LEN(body) + SP + body
+func PAE(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
@@ -0,0 +1,77 @@
+package intoto
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPAE(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("DSSEv1 29 http://example.com/HelloWorld 11 hello world", string(PAE("http://example.com/HelloWorld", []byte("hello world"))))
+	assert.Equal("DSSEv1 0  0 ", string(PAE("", nil)))
+}
+
+func TestNewEnvelope(t *testing.T) {
+	assert := assert.New(t)
+
+	stmt := SLSAProvenanceStatement(
+		WithSubject([]Subject{{Name: "salsa.txt", Digest: DigestSet{"sha256": "f8161d035cdf328c7bb124fce192cb90b603f34ca78d73e33b736b4f6bddf993"}}}),
+		WithBuilder(builderID),
+	)
+
+	env, err := NewEnvelope(stmt)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal(PayloadType, env.PayloadType)
+	assert.NotNil(env.Signatures)
+	assert.Len(env.Signatures, 0)
+
+	payload, err := base64.StdEncoding.DecodeString(env.Payload)
+	assert.NoError(err)
+	stmtJSON, err := json.Marshal(stmt)
+	assert.NoError(err)
+	assert.Equal(stmtJSON, payload)
+
+	pae, err := env.PAE()
+	assert.NoError(err)
+	assert.Equal(PAE(PayloadType, stmtJSON), pae)
+
+	decoded, err := env.Statement()
+	if assert.NoError(err) {
+		assert.Equal(stmt.PredicateType, decoded.PredicateType)
+		assert.Equal(stmt.Subject, decoded.Subject)
+		assert.Equal(stmt.Predicate.Builder, decoded.Predicate.Builder)
+	}
+
+	envJSON, err := json.Marshal(env)
+	assert.NoError(err)
+	assert.Contains(string(envJSON), `"signatures":[]`)
+
+	env.Signatures = append(env.Signatures, Signature{KeyID: "my-key", Sig: "c2lnbmF0dXJl"})
+	envJSON, err = json.Marshal(env)
+	assert.NoError(err)
+	assert.Contains(string(envJSON), `"signatures":[{"keyid":"my-key","sig":"c2lnbmF0dXJl"}]`)
+}
+
+func TestEnvelopeErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	env := &Envelope{PayloadType: "application/json", Payload: "e30="}
+	stmt, err := env.Statement()
+	assert.EqualError(err, `unsupported payloadType "application/json", expected "application/vnd.in-toto+json"`)
+	assert.Nil(stmt)
+
+	env = &Envelope{PayloadType: PayloadType, Payload: "not base64!"}
+	stmt, err = env.Statement()
+	assert.EqualError(err, "failed to decode envelope payload: illegal base64 data at input byte 3")
+	assert.Nil(stmt)
+
+	pae, err := env.PAE()
+	assert.Error(err)
+	assert.Nil(pae)
+}
@@ -9,8 +9,17 @@ import (
 const (
 	// SlsaPredicateType the predicate type for SLSA intoto statements
 	SlsaPredicateType = "https://slsa.dev/provenance/v0.2"
+	// SlsaPredicateTypeV1 the predicate type for SLSA v1.0 intoto statements
+	SlsaPredicateTypeV1 = "https://slsa.dev/provenance/v1"
 	// StatementType the type of the intoto statement
 	StatementType = "https://in-toto.io/Statement/v0.1"
+	// StatementTypeV1 the type of the intoto v1 statement
+	StatementTypeV1 = "https://in-toto.io/Statement/v1"
+
+	// PredicateVersionV02 selects the SLSA v0.2 provenance predicate
+	PredicateVersionV02 = "v0.2"
+	// PredicateVersionV1 selects the SLSA v1.0 provenance predicate
+	PredicateVersionV1 = "v1"
 )
 
 // Provenancer generates provenance statements for given artifacts
@@ -19,13 +28,6 @@ type Provenancer interface {
 	PersistProvenanceStatement(ctx context.Context, stmt *Statement, path string) error
 }
 
-// Envelope wraps an in-toto statement to be able to attach signatures to the Statement
-type Envelope struct {
-	PayloadType string        `json:"payloadType"`
-	Payload     string        `json:"payload"`
-	Signatures  []interface{} `json:"signatures"`
-}
-
 // SLSAProvenanceStatement builds a in-toto statement with predicate type https://slsa.dev/provenance/v0.1
 func SLSAProvenanceStatement(opts ...StatementOption) *Statement {
 	stmt := &Statement{PredicateType: SlsaPredicateType, Type: StatementType}
@@ -86,17 +88,82 @@ func WithInvocation(buildType, entryPoint string, environment json.RawMessage, p
 }
 
 // Statement The Statement is the middle layer of the attestation, binding it to a particular subject and unambiguously identifying the types of the predicate.
+//
+// Predicate holds the SLSA v0.2 predicate. PredicateV1 holds the SLSA v1.0 predicate and is
+// used instead of Predicate when the PredicateType is SlsaPredicateTypeV1. VerificationSummary
+// holds the SLSA VSA predicate when the PredicateType is VerificationSummaryPredicateType.
 type Statement struct {
-	Type          string    `json:"_type"`
-	Subject       []Subject `json:"subject"`
-	PredicateType string    `json:"predicateType"`
-	Predicate     Predicate `json:"predicate"`
+	Type          string        `json:"_type"`
+	Subject       []Subject     `json:"subject"`
+	PredicateType string        `json:"predicateType"`
+	Predicate     Predicate     `json:"predicate"`
+	PredicateV1   *ProvenanceV1 `json:"-"`
+
+	VerificationSummary *VerificationSummary `json:"-"`
+}
+
+type statementJSON struct {
+	Type          string          `json:"_type"`
+	Subject       []Subject       `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+// MarshalJSON marshals the Statement using the predicate matching the PredicateType
+func (s Statement) MarshalJSON() ([]byte, error) {
+	var predicate interface{} = s.Predicate
+	switch s.PredicateType {
+	case SlsaPredicateTypeV1:
+		predicate = s.PredicateV1
+	case VerificationSummaryPredicateType:
+		predicate = s.VerificationSummary
+	}
+
+	p, err := json.Marshal(predicate)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(statementJSON{
+		Type:          s.Type,
+		Subject:       s.Subject,
+		PredicateType: s.PredicateType,
+		Predicate:     p,
+	})
+}
+
+// UnmarshalJSON unmarshals the Statement into the predicate matching the PredicateType
+func (s *Statement) UnmarshalJSON(data []byte) error {
+	var raw statementJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	s.Type = raw.Type
+	s.Subject = raw.Subject
+	s.PredicateType = raw.PredicateType
+
+	if len(raw.Predicate) == 0 || string(raw.Predicate) == "null" {
+		return nil
+	}
+
+	switch raw.PredicateType {
+	case SlsaPredicateTypeV1:
+		s.PredicateV1 = &ProvenanceV1{}
+		return json.Unmarshal(raw.Predicate, s.PredicateV1)
+	case VerificationSummaryPredicateType:
+		s.VerificationSummary = &VerificationSummary{}
+		return json.Unmarshal(raw.Predicate, s.VerificationSummary)
+	default:
+		return json.Unmarshal(raw.Predicate, &s.Predicate)
+	}
 }
 
 // Subject The software artifacts that the attestation applies to.
 type Subject struct {
-	Name   string    `json:"name"`
-	Digest DigestSet `json:"digest"`
+	Name        string            `json:"name"`
+	Digest      DigestSet         `json:"digest"`
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // Predicate This predicate follows the in-toto attestation parsing rules.
@@ -7,8 +7,19 @@ import (
 )
 
 // WithMaterials adds additional materials to the predicate
+//
+// For SLSA v1.0 statements the materials are added as resolvedDependencies.
 func WithMaterials(materials []Item) StatementOption {
 	return func(s *Statement) {
+		if s.PredicateV1 != nil {
+			for _, m := range materials {
+				s.PredicateV1.BuildDefinition.ResolvedDependencies = append(
+					s.PredicateV1.BuildDefinition.ResolvedDependencies,
+					ResourceDescriptor{URI: m.URI, Digest: m.Digest},
+				)
+			}
+			return
+		}
 		s.Predicate.Materials = append(s.Predicate.Materials, materials...)
 	}
 }
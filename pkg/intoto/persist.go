@@ -0,0 +1,21 @@
+package intoto
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// WriteProvenance writes the statement, e.g. provenance or a verification summary, or envelope as indented JSON at
+// the given path
+func WriteProvenance(v interface{}, path string) error {
+	payload, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal provenance: %w", err)
+	}
+	if err := os.WriteFile(path, payload, 0755); err != nil {
+		return fmt.Errorf("failed to write provenance: %w", err)
+	}
+
+	return nil
+}
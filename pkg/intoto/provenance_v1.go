@@ -0,0 +1,90 @@
+package intoto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SLSAProvenanceStatementV1 builds a in-toto statement with predicate type https://slsa.dev/provenance/v1
+func SLSAProvenanceStatementV1(opts ...StatementOption) *Statement {
+	stmt := &Statement{PredicateType: SlsaPredicateTypeV1, Type: StatementTypeV1, PredicateV1: &ProvenanceV1{}}
+	for _, opt := range opts {
+		opt(stmt)
+	}
+	return stmt
+}
+
+// WithBuildDefinition sets the SLSA v1.0 BuildDefinition
+func WithBuildDefinition(buildType string, externalParameters, internalParameters json.RawMessage, resolvedDependencies []ResourceDescriptor) StatementOption {
+	return func(s *Statement) {
+		s.PredicateV1.BuildDefinition.BuildType = buildType
+		s.PredicateV1.BuildDefinition.ExternalParameters = externalParameters
+		s.PredicateV1.BuildDefinition.InternalParameters = internalParameters
+		s.PredicateV1.BuildDefinition.ResolvedDependencies = append(s.PredicateV1.BuildDefinition.ResolvedDependencies, resolvedDependencies...)
+	}
+}
+
+// WithRunDetails sets the SLSA v1.0 RunDetails using the builderID, invocationID and the current time
+func WithRunDetails(builderID, invocationID string) StatementOption {
+	return func(s *Statement) {
+		s.PredicateV1.RunDetails.Builder = BuilderV1{ID: builderID}
+		s.PredicateV1.RunDetails.Metadata = BuildMetadata{
+			InvocationID: invocationID,
+			FinishedOn:   time.Now().UTC().Format(time.RFC3339),
+		}
+	}
+}
+
+// WithByproducts adds byproducts to the SLSA v1.0 RunDetails
+func WithByproducts(byproducts []ResourceDescriptor) StatementOption {
+	return func(s *Statement) {
+		s.PredicateV1.RunDetails.Byproducts = append(s.PredicateV1.RunDetails.Byproducts, byproducts...)
+	}
+}
+
+// ProvenanceV1 The SLSA v1.0 provenance predicate.
+//
+// https://slsa.dev/spec/v1.0/provenance
+type ProvenanceV1 struct {
+	BuildDefinition BuildDefinition `json:"buildDefinition"`
+	RunDetails      RunDetails      `json:"runDetails"`
+}
+
+// BuildDefinition The input to the build. The accuracy and completeness are implied by runDetails.builder.id.
+type BuildDefinition struct {
+	BuildType            string               `json:"buildType"`
+	ExternalParameters   json.RawMessage      `json:"externalParameters"`
+	InternalParameters   json.RawMessage      `json:"internalParameters,omitempty"`
+	ResolvedDependencies []ResourceDescriptor `json:"resolvedDependencies,omitempty"`
+}
+
+// RunDetails Details specific to this particular execution of the build.
+type RunDetails struct {
+	Builder    BuilderV1            `json:"builder"`
+	Metadata   BuildMetadata        `json:"metadata"`
+	Byproducts []ResourceDescriptor `json:"byproducts,omitempty"`
+}
+
+// BuilderV1 Identifies the build platform that executed the invocation, which is trusted to have correctly performed the operation and populated this provenance.
+type BuilderV1 struct {
+	ID                  string               `json:"id"`
+	Version             map[string]string    `json:"version,omitempty"`
+	BuilderDependencies []ResourceDescriptor `json:"builderDependencies,omitempty"`
+}
+
+// BuildMetadata Metadata about this particular execution of the build.
+type BuildMetadata struct {
+	InvocationID string `json:"invocationId,omitempty"`
+	StartedOn    string `json:"startedOn,omitempty"`
+	FinishedOn   string `json:"finishedOn,omitempty"`
+}
+
+// ResourceDescriptor Describes a software artifact or resource, used for resolvedDependencies and byproducts.
+type ResourceDescriptor struct {
+	URI              string            `json:"uri,omitempty"`
+	Digest           DigestSet         `json:"digest,omitempty"`
+	Name             string            `json:"name,omitempty"`
+	DownloadLocation string            `json:"downloadLocation,omitempty"`
+	MediaType        string            `json:"mediaType,omitempty"`
+	Annotations      map[string]string `json:"annotations,omitempty"`
+}
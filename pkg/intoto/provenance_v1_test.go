@@ -0,0 +1,129 @@
+package intoto
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	buildTypeV1 = "https://slsa-framework.github.io/github-actions-buildtypes/workflow/v1"
+)
+
+func TestSLSAProvenanceStatementV1(t *testing.T) {
+	assert := assert.New(t)
+
+	stmt := SLSAProvenanceStatementV1()
+	assert.Equal(SlsaPredicateTypeV1, stmt.PredicateType)
+	assert.Equal(StatementTypeV1, stmt.Type)
+	assert.Len(stmt.Subject, 0)
+	assert.NotNil(stmt.PredicateV1)
+
+	dependencies := []ResourceDescriptor{
+		{
+			URI:    "git+https://github.com/philips-labs/slsa-provenance-action@refs/heads/main",
+			Digest: DigestSet{"gitCommit": "c4f679f131dfb7f810fd411ac9475549d1c393df"},
+		},
+	}
+	materials := []Item{
+		{
+			URI:    "pkg:deb/debian/stunnel4@5.50-3?arch=amd64",
+			Digest: DigestSet{"sha256": "e1731ae217fcbc64d4c00d707dcead45c828c5f762bcf8cc56d87de511e096fa"},
+		},
+	}
+
+	stmt = SLSAProvenanceStatementV1(
+		WithSubject([]Subject{{Name: "salsa.txt", Digest: DigestSet{"sha256": "f8161d035cdf328c7bb124fce192cb90b603f34ca78d73e33b736b4f6bddf993"}}}),
+		WithRunDetails(builderID, buildInvocationID),
+		WithBuildDefinition(buildTypeV1, []byte(`{"workflow":{"path":"ci.yaml"}}`), nil, dependencies),
+		WithMaterials(materials),
+		WithByproducts([]ResourceDescriptor{{Name: "build.log"}}),
+	)
+
+	p := stmt.PredicateV1
+	assert.Len(stmt.Subject, 1)
+	assert.Equal(builderID, p.RunDetails.Builder.ID)
+	assert.Equal(buildInvocationID, p.RunDetails.Metadata.InvocationID)
+	bft, err := time.Parse(time.RFC3339, p.RunDetails.Metadata.FinishedOn)
+	assert.NoError(err)
+	assert.WithinDuration(time.Now().UTC(), bft, 1200*time.Millisecond)
+	assert.Equal(buildTypeV1, p.BuildDefinition.BuildType)
+	assert.JSONEq(`{"workflow":{"path":"ci.yaml"}}`, string(p.BuildDefinition.ExternalParameters))
+	assert.Nil(p.BuildDefinition.InternalParameters)
+	assert.Len(p.BuildDefinition.ResolvedDependencies, 2)
+	assert.Equal(dependencies[0], p.BuildDefinition.ResolvedDependencies[0])
+	assert.Equal(ResourceDescriptor{URI: materials[0].URI, Digest: materials[0].Digest}, p.BuildDefinition.ResolvedDependencies[1])
+	assert.Equal([]ResourceDescriptor{{Name: "build.log"}}, p.RunDetails.Byproducts)
+	assert.Empty(stmt.Predicate.Materials)
+}
+
+func TestSLSAProvenanceStatementV1JSON(t *testing.T) {
+	assert := assert.New(t)
+
+	jsonStatement := `{
+	"_type": "https://in-toto.io/Statement/v1",
+	"subject": [
+		{
+			"name": "salsa.txt",
+			"digest": {
+				"sha256": "f8161d035cdf328c7bb124fce192cb90b603f34ca78d73e33b736b4f6bddf993"
+			}
+		}
+	],
+	"predicateType": "https://slsa.dev/provenance/v1",
+	"predicate": {
+		"buildDefinition": {
+			"buildType": "https://slsa-framework.github.io/github-actions-buildtypes/workflow/v1",
+			"externalParameters": {
+				"workflow": {
+					"ref": "refs/heads/main",
+					"repository": "https://github.com/philips-labs/slsa-provenance-action",
+					"path": ".github/workflows/ci.yaml"
+				}
+			},
+			"internalParameters": {
+				"github": {
+					"event_name": "push",
+					"repository_owner": "philips-labs"
+				}
+			},
+			"resolvedDependencies": [
+				{
+					"uri": "git+https://github.com/philips-labs/slsa-provenance-action@refs/heads/main",
+					"digest": {
+						"gitCommit": "a3bc1c27230caa1cc3c27961f7e9cab43cd208dc"
+					}
+				}
+			]
+		},
+		"runDetails": {
+			"builder": {
+				"id": "https://github.com/philips-labs/slsa-provenance-action/Attestations/GitHubHostedActions@v1"
+			},
+			"metadata": {
+				"invocationId": "https://github.com/philips-labs/slsa-provenance-action/actions/runs/123498765",
+				"finishedOn": "2021-10-12T12:18:06Z"
+			}
+		}
+	}
+}`
+
+	var stmt Statement
+	err := json.Unmarshal([]byte(jsonStatement), &stmt)
+	if !assert.NoError(err) || !assert.NotNil(stmt.PredicateV1) {
+		return
+	}
+	assert.Equal(SlsaPredicateTypeV1, stmt.PredicateType)
+	assert.Equal(StatementTypeV1, stmt.Type)
+	assert.Equal(builderID, stmt.PredicateV1.RunDetails.Builder.ID)
+	assert.Equal(buildInvocationID, stmt.PredicateV1.RunDetails.Metadata.InvocationID)
+	assert.Equal(buildTypeV1, stmt.PredicateV1.BuildDefinition.BuildType)
+	assert.Len(stmt.PredicateV1.BuildDefinition.ResolvedDependencies, 1)
+	assert.Equal(Predicate{}, stmt.Predicate)
+
+	stmtJSON, err := json.MarshalIndent(&stmt, "", "\t")
+	assert.NoError(err)
+	assert.Equal(jsonStatement, string(stmtJSON))
+}
@@ -0,0 +1,111 @@
+package intoto
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BuilderID returns the builder id of the SLSA v0.2 or v1.0 predicate
+func (s *Statement) BuilderID() string {
+	if s.PredicateV1 != nil {
+		return s.PredicateV1.RunDetails.Builder.ID
+	}
+	return s.Predicate.Builder.ID
+}
+
+// BuildType returns the buildType of the SLSA v0.2 or v1.0 predicate
+func (s *Statement) BuildType() string {
+	if s.PredicateV1 != nil {
+		return s.PredicateV1.BuildDefinition.BuildType
+	}
+	return s.Predicate.BuildType
+}
+
+// Materials returns the materials of the SLSA v0.2 predicate or the resolvedDependencies of the SLSA v1.0 predicate
+func (s *Statement) Materials() []Item {
+	if s.PredicateV1 == nil {
+		return s.Predicate.Materials
+	}
+
+	var materials []Item
+	for _, d := range s.PredicateV1.BuildDefinition.ResolvedDependencies {
+		materials = append(materials, Item{URI: d.URI, Digest: d.Digest})
+	}
+	return materials
+}
+
+// Source returns the source repository and ref the build was executed from.
+//
+// For SLSA v0.2 the configSource is used, for SLSA v1.0 the first git resolvedDependency.
+// The ref is empty when it isn't recorded in the uri (e.g. git+https://github.com/owner/repo@refs/heads/main).
+func (s *Statement) Source() (repo, ref string) {
+	uri := s.Predicate.Invocation.ConfigSource.URI
+	if s.PredicateV1 != nil {
+		uri = ""
+		for _, d := range s.PredicateV1.BuildDefinition.ResolvedDependencies {
+			if strings.HasPrefix(d.URI, "git+") {
+				uri = d.URI
+				break
+			}
+		}
+	}
+
+	return ParseSourceURI(uri)
+}
+
+// SourceURI formats the git source uri including the ref when known, e.g. git+https://github.com/owner/repo@refs/heads/main
+func SourceURI(repo, ref string) string {
+	if ref == "" {
+		return "git+" + repo
+	}
+	return fmt.Sprintf("git+%s@%s", repo, ref)
+}
+
+// ParseSourceURI splits a git source uri like git+https://github.com/owner/repo@refs/heads/main
+// into the repository https://github.com/owner/repo and the ref refs/heads/main.
+func ParseSourceURI(uri string) (repo, ref string) {
+	repo = strings.TrimPrefix(uri, "git+")
+
+	// NOTE: only look for the ref in the path, the host can hold a user, e.g. ssh://git@github.com/owner/repo
+	pathStart := 0
+	if i := strings.Index(repo, "://"); i >= 0 {
+		pathStart = i + len("://")
+	}
+	if i := strings.Index(repo[pathStart:], "/"); i >= 0 {
+		pathStart += i
+	}
+	if i := strings.LastIndex(repo, "@"); i > pathStart {
+		repo, ref = repo[:i], repo[i+1:]
+	}
+
+	return repo, ref
+}
+
+// NormalizeRepoURI strips the git+ prefix, scheme, trailing slash and .git suffix of a repository uri,
+// e.g. git+https://github.com/Owner/repo.git becomes github.com/owner/repo.
+func NormalizeRepoURI(repo string) string {
+	repo = strings.TrimPrefix(repo, "git+")
+	if i := strings.Index(repo, "://"); i >= 0 {
+		repo = repo[i+len("://"):]
+	}
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSuffix(repo, "/"), ".git"))
+}
+
+// MatchRef compares the refs, a short ref like "main" or "v1.0.0" matches refs/heads/main or refs/tags/v1.0.0
+func MatchRef(ref, expected string) bool {
+	if ref == expected {
+		return true
+	}
+	if strings.HasPrefix(expected, "refs/") {
+		return false
+	}
+	return ref == "refs/heads/"+expected || ref == "refs/tags/"+expected
+}
+
+// FinishedOn returns the build finished timestamp of the SLSA v0.2 or v1.0 predicate
+func (s *Statement) FinishedOn() string {
+	if s.PredicateV1 != nil {
+		return s.PredicateV1.RunDetails.Metadata.FinishedOn
+	}
+	return s.Predicate.Metadata.BuildFinishedOn
+}
@@ -0,0 +1,65 @@
+package intoto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatementAccessors(t *testing.T) {
+	assert := assert.New(t)
+
+	repoURI := "https://github.com/philips-labs/slsa-provenance-action"
+	material := Item{URI: "git+" + repoURI + "@refs/heads/main", Digest: DigestSet{"sha1": "a3bc1c27230caa1cc3c27961f7e9cab43cd208dc"}}
+
+	stmt := SLSAProvenanceStatement(
+		WithBuilder(repoURI+"/Attestations/GitHubHostedActions@v1"),
+		WithInvocation("https://mybuildtype", "CI workflow", nil, nil, []Item{material}),
+	)
+	assert.Equal(repoURI+"/Attestations/GitHubHostedActions@v1", stmt.BuilderID())
+	assert.Equal("https://mybuildtype", stmt.BuildType())
+	assert.Equal([]Item{material}, stmt.Materials())
+	repo, ref := stmt.Source()
+	assert.Equal(repoURI, repo)
+	assert.Equal("refs/heads/main", ref)
+
+	stmtV1 := SLSAProvenanceStatementV1(
+		WithBuildDefinition("https://mybuildtype/v1", nil, nil, []ResourceDescriptor{
+			{URI: "pkg:docker/alpine@3.15", Digest: DigestSet{"sha256": "abc"}},
+			{URI: material.URI, Digest: DigestSet{"gitCommit": "a3bc1c27230caa1cc3c27961f7e9cab43cd208dc"}},
+		}),
+		WithRunDetails(repoURI+"/Attestations/GitHubHostedActions@v1", "1"),
+	)
+	assert.Equal(repoURI+"/Attestations/GitHubHostedActions@v1", stmtV1.BuilderID())
+	assert.Equal("https://mybuildtype/v1", stmtV1.BuildType())
+	assert.Len(stmtV1.Materials(), 2)
+	repo, ref = stmtV1.Source()
+	assert.Equal(repoURI, repo)
+	assert.Equal("refs/heads/main", ref)
+}
+
+func TestParseSourceURI(t *testing.T) {
+	testCases := []struct {
+		uri  string
+		repo string
+		ref  string
+	}{
+		{"git+https://github.com/owner/repo@refs/tags/v1.0.0", "https://github.com/owner/repo", "refs/tags/v1.0.0"},
+		{"git+https://github.com/owner/repo", "https://github.com/owner/repo", ""},
+		{"git+ssh://git@github.com/owner/repo", "ssh://git@github.com/owner/repo", ""},
+		{"git+ssh://git@github.com/owner/repo@main", "ssh://git@github.com/owner/repo", "main"},
+		{"", "", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.uri, func(tt *testing.T) {
+			assert := assert.New(tt)
+			repo, ref := ParseSourceURI(tc.uri)
+			assert.Equal(tc.repo, repo)
+			assert.Equal(tc.ref, ref)
+			if tc.uri != "" {
+				assert.Equal(tc.uri, SourceURI(repo, ref))
+			}
+		})
+	}
+}
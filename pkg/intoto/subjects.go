@@ -1,11 +1,18 @@
 package intoto
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
+	"context"
+	"fmt"
 	"io/fs"
-	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/digest"
 )
 
 // Subjecter retrieves subjects
@@ -13,51 +20,221 @@ type Subjecter interface {
 	Subjects() ([]Subject, error)
 }
 
+// FilePathSubjecterOption option flag to configure the FilePathSubjecter
+type FilePathSubjecterOption func(*FilePathSubjecter)
+
+// WithIncludePatterns only hashes the files matching any of the doublestar glob patterns, e.g. **/*.tar.gz
+//
+// Patterns are matched against the path relative to the root, using forward slashes.
+func WithIncludePatterns(patterns ...string) FilePathSubjecterOption {
+	return func(f *FilePathSubjecter) {
+		f.includes = append(f.includes, patterns...)
+	}
+}
+
+// WithExcludePatterns skips the files matching any of the doublestar glob patterns, e.g. **/*.sig
+//
+// Patterns are matched against the path relative to the root, using forward slashes. Excludes take precedence over includes.
+func WithExcludePatterns(patterns ...string) FilePathSubjecterOption {
+	return func(f *FilePathSubjecter) {
+		f.excludes = append(f.excludes, patterns...)
+	}
+}
+
+// WithConcurrency sets the number of files hashed in parallel, defaults to the number of CPUs
+func WithConcurrency(n int) FilePathSubjecterOption {
+	return func(f *FilePathSubjecter) {
+		if n > 0 {
+			f.concurrency = n
+		}
+	}
+}
+
+// WithDigestAlgorithms sets the digest algorithms to hash each file with, defaults to sha256
+func WithDigestAlgorithms(algorithms ...string) FilePathSubjecterOption {
+	return func(f *FilePathSubjecter) {
+		if len(algorithms) > 0 {
+			f.algorithms = algorithms
+		}
+	}
+}
+
+// WithArchiveMembers also hashes the members of .tar, .tar.gz, .tgz, .tar.zst and .zip archives,
+// named like release.tar.gz!/bin/app. The limits guard against decompression bombs, unset limits default
+// to the DefaultArchiveLimits.
+func WithArchiveMembers(limits ArchiveLimits) FilePathSubjecterOption {
+	return func(f *FilePathSubjecter) {
+		if limits.MaxMembers <= 0 {
+			limits.MaxMembers = DefaultArchiveLimits.MaxMembers
+		}
+		if limits.MaxSize <= 0 {
+			limits.MaxSize = DefaultArchiveLimits.MaxSize
+		}
+		if limits.MaxRatio <= 0 {
+			limits.MaxRatio = DefaultArchiveLimits.MaxRatio
+		}
+		f.archiveMembers = true
+		f.archiveLimits = limits
+	}
+}
+
 // FilePathSubjecter implements Subjector to retrieve Subject from filepath
 type FilePathSubjecter struct {
-	root string
+	root           string
+	includes       []string
+	excludes       []string
+	concurrency    int
+	algorithms     []string
+	archiveMembers bool
+	archiveLimits  ArchiveLimits
 }
 
 // NewFilePathSubjecter walks the file or directory at "root" and hashes all files.
-func NewFilePathSubjecter(root string) *FilePathSubjecter {
-	return &FilePathSubjecter{root}
+func NewFilePathSubjecter(root string, opts ...FilePathSubjecterOption) *FilePathSubjecter {
+	f := &FilePathSubjecter{root: root, concurrency: runtime.NumCPU(), algorithms: digest.Default}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// ValidatePatterns checks the include and exclude patterns are valid doublestar glob patterns
+func ValidatePatterns(patterns ...string) error {
+	for _, p := range patterns {
+		if !doublestar.ValidatePattern(p) {
+			return fmt.Errorf("invalid glob pattern %q", p)
+		}
+	}
+	return nil
 }
 
-// Subjects walks the file or directory at "root" and hashes all files.
+// Subjects walks the file or directory at "root" and hashes all files matching the include and exclude patterns.
+//
+// Files are streamed through the hashers of all digest algorithms in a single pass using a bounded pool of workers. The subjects are sorted by their
+// relative path, so the result is deterministic regardless of the concurrency. When archive members are
+// enabled, the member subjects directly follow the subject of their archive.
 func (f *FilePathSubjecter) Subjects() ([]Subject, error) {
+	if err := ValidatePatterns(append(f.includes, f.excludes...)...); err != nil {
+		return nil, err
+	}
+	if err := digest.Validate(f.algorithms...); err != nil {
+		return nil, err
+	}
+
+	files, err := f.files()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([][]Subject, len(files))
+	g, ctx := errgroup.WithContext(context.Background())
+	g.SetLimit(f.concurrency)
+	for i, file := range files {
+		g.Go(func() error {
+			// NOTE: skip the remaining files once hashing one of the files failed.
+			if ctx.Err() != nil {
+				return nil
+			}
+			digests, err := digest.FromFile(file.abspath, f.algorithms...)
+			if err != nil {
+				return err
+			}
+			results[i] = []Subject{{Name: file.relpath, Digest: DigestSet(digests)}}
+
+			if f.archiveMembers {
+				members, err := archiveMembers(file.abspath, filepath.ToSlash(file.relpath), f.algorithms, f.archiveLimits)
+				if err != nil {
+					return err
+				}
+				results[i] = append(results[i], members...)
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
 	var s []Subject
-	return s, filepath.Walk(f.root, func(abspath string, info fs.FileInfo, err error) error {
+	for _, r := range results {
+		s = append(s, r...)
+	}
+	return s, nil
+}
+
+type subjectFile struct {
+	relpath string
+	abspath string
+}
+
+// files walks the file or directory at "root" and returns the files matching the patterns sorted by relative path
+func (f *FilePathSubjecter) files() ([]subjectFile, error) {
+	var files []subjectFile
+	err := filepath.Walk(f.root, func(abspath string, info fs.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		if info.IsDir() {
-			return nil
-		}
 		relpath, err := filepath.Rel(f.root, abspath)
 		if err != nil {
 			return err
 		}
+		if info.IsDir() {
+			if relpath != "." && f.excludesDir(filepath.ToSlash(relpath)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 		// Note: filepath.Rel() returns "." when "root" and "abspath" point to the same file.
 		if relpath == "." {
 			relpath = filepath.Base(f.root)
 		}
-
-		binary, err := os.ReadFile(abspath)
-		if err != nil {
-			return err
+		if !f.matches(filepath.ToSlash(relpath)) {
+			return nil
 		}
 
-		shaHex := ShaSum256HexEncoded(binary)
-
-		s = append(s, Subject{Name: relpath, Digest: DigestSet{"sha256": shaHex}})
+		files = append(files, subjectFile{relpath, abspath})
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].relpath < files[j].relpath })
+	return files, nil
+}
+
+// excludesDir reports whether all files in the directory are excluded by a pattern like node_modules/**,
+// so the directory doesn't have to be walked
+func (f *FilePathSubjecter) excludesDir(relpath string) bool {
+	for _, p := range f.excludes {
+		if dir, ok := strings.CutSuffix(p, "/**"); ok && doublestar.MatchUnvalidated(dir, relpath) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether the relative path is included and not excluded
+func (f *FilePathSubjecter) matches(relpath string) bool {
+	for _, p := range f.excludes {
+		if doublestar.MatchUnvalidated(p, relpath) {
+			return false
+		}
+	}
+	if len(f.includes) == 0 {
+		return true
+	}
+	for _, p := range f.includes {
+		if doublestar.MatchUnvalidated(p, relpath) {
+			return true
+		}
+	}
+	return false
 }
 
 // ShaSum256HexEncoded calculates a SHA256 checksum from the content
+//
+// Deprecated: use digest.SHA256Hex instead.
 func ShaSum256HexEncoded(b []byte) string {
-	sha := sha256.Sum256(b)
-	shaHex := hex.EncodeToString(sha[:])
-
-	return shaHex
+	return digest.SHA256Hex(b)
 }
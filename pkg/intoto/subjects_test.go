@@ -1,12 +1,17 @@
 package intoto
 
 import (
+	"bytes"
+	"fmt"
 	"os"
 	"path"
 	"runtime"
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/digest"
 )
 
 func TestSubjects(t *testing.T) {
@@ -42,13 +47,165 @@ func TestSubjects(t *testing.T) {
 	assert.NoError(err)
 	assert.NotNil(s)
 
-	assert.Len(s, 6)
+	assert.Len(s, 19)
+	assertSubject(assert, s, "archive_test.go", path.Join(".", "archive_test.go"))
+	assertSubject(assert, s, "archive.go", path.Join(".", "archive.go"))
+	assertSubject(assert, s, "checksums_test.go", path.Join(".", "checksums_test.go"))
+	assertSubject(assert, s, "checksums.go", path.Join(".", "checksums.go"))
+	assertSubject(assert, s, "envelope_test.go", path.Join(".", "envelope_test.go"))
+	assertSubject(assert, s, "envelope.go", path.Join(".", "envelope.go"))
 	assertSubject(assert, s, "intoto_test.go", path.Join(".", "intoto_test.go"))
 	assertSubject(assert, s, "intoto.go", path.Join(".", "intoto.go"))
+	assertSubject(assert, s, "persist.go", path.Join(".", "persist.go"))
 	assertSubject(assert, s, "subjects_test.go", path.Join(".", "subjects_test.go"))
 	assertSubject(assert, s, "subjects.go", path.Join(".", "subjects.go"))
 	assertSubject(assert, s, "materials_test.go", path.Join(".", "materials_test.go"))
 	assertSubject(assert, s, "materials.go", path.Join(".", "materials.go"))
+	assertSubject(assert, s, "provenance_v1_test.go", path.Join(".", "provenance_v1_test.go"))
+	assertSubject(assert, s, "provenance_v1.go", path.Join(".", "provenance_v1.go"))
+	assertSubject(assert, s, "statement_test.go", path.Join(".", "statement_test.go"))
+	assertSubject(assert, s, "statement.go", path.Join(".", "statement.go"))
+	assertSubject(assert, s, "vsa_test.go", path.Join(".", "vsa_test.go"))
+	assertSubject(assert, s, "vsa.go", path.Join(".", "vsa.go"))
+}
+
+func TestSubjectsIncludeExclude(t *testing.T) {
+	root := t.TempDir()
+	for _, f := range []string{"app.tar.gz", "app.tar.gz.sig", "build.log", "docs/README.md", "nested/deep/lib.tar.gz", "tmp/scratch.tar.gz"} {
+		assert.NoError(t, os.MkdirAll(path.Join(root, path.Dir(f)), 0755))
+		assert.NoError(t, os.WriteFile(path.Join(root, f), []byte(f), 0644))
+	}
+
+	testCases := []struct {
+		name     string
+		opts     []FilePathSubjecterOption
+		expected []string
+	}{
+		{
+			name:     "no patterns",
+			expected: []string{"app.tar.gz", "app.tar.gz.sig", "build.log", "docs/README.md", "nested/deep/lib.tar.gz", "tmp/scratch.tar.gz"},
+		},
+		{
+			name:     "include",
+			opts:     []FilePathSubjecterOption{WithIncludePatterns("**/*.tar.gz")},
+			expected: []string{"app.tar.gz", "nested/deep/lib.tar.gz", "tmp/scratch.tar.gz"},
+		},
+		{
+			name:     "exclude",
+			opts:     []FilePathSubjecterOption{WithExcludePatterns("**/*.sig", "*.log", "tmp/**")},
+			expected: []string{"app.tar.gz", "docs/README.md", "nested/deep/lib.tar.gz"},
+		},
+		{
+			name:     "exclude takes precedence over include",
+			opts:     []FilePathSubjecterOption{WithIncludePatterns("**/*.{tar.gz,md}"), WithExcludePatterns("tmp/**", "docs/*")},
+			expected: []string{"app.tar.gz", "nested/deep/lib.tar.gz"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			assert := assert.New(tt)
+
+			s, err := NewFilePathSubjecter(root, tc.opts...).Subjects()
+			assert.NoError(err)
+
+			names := make([]string, len(s))
+			for i, subject := range s {
+				names[i] = subject.Name
+			}
+			assert.Equal(tc.expected, names)
+		})
+	}
+
+	_, err := NewFilePathSubjecter(root, WithIncludePatterns("[")).Subjects()
+	assert.EqualError(t, err, `invalid glob pattern "["`)
+
+	s, err := NewFilePathSubjecter(path.Join(root, "app.tar.gz"), WithExcludePatterns("*.tar.gz")).Subjects()
+	assert.NoError(t, err)
+	assert.Empty(t, s)
+}
+
+func TestSubjectsExcludedDirectory(t *testing.T) {
+	assert := assert.New(t)
+
+	f := NewFilePathSubjecter(".", WithExcludePatterns("node_modules/**", "**/vendor/**", "dist/*", "*.log"))
+	assert.True(f.excludesDir("node_modules"))
+	assert.True(f.excludesDir("cmd/vendor"))
+	assert.False(f.excludesDir("dist"))
+	assert.False(f.excludesDir("build.log"))
+	assert.False(f.excludesDir("src"))
+
+	if os.Getuid() == 0 {
+		t.Skip("file permissions are not enforced for root")
+	}
+
+	// NOTE: walking the unreadable directory fails, so it has to be skipped.
+	root := t.TempDir()
+	assert.NoError(os.WriteFile(path.Join(root, "app.tar.gz"), []byte("app"), 0644))
+	assert.NoError(os.Mkdir(path.Join(root, "node_modules"), 0000))
+
+	s, err := NewFilePathSubjecter(root, WithExcludePatterns("node_modules/**")).Subjects()
+	assert.NoError(err)
+	assert.Len(s, 1)
+}
+
+func TestSubjectsConcurrency(t *testing.T) {
+	assert := assert.New(t)
+
+	root := t.TempDir()
+	var expected []Subject
+	for i := 0; i < 50; i++ {
+		name := fmt.Sprintf("dir-%d/artifact-%02d.bin", i%3, i)
+		content := bytes.Repeat([]byte{byte(i)}, 1024*i)
+		assert.NoError(os.MkdirAll(path.Join(root, path.Dir(name)), 0755))
+		assert.NoError(os.WriteFile(path.Join(root, name), content, 0644))
+		expected = append(expected, Subject{Name: name, Digest: DigestSet{"sha256": digest.SHA256Hex(content)}})
+	}
+	sort.Slice(expected, func(i, j int) bool { return expected[i].Name < expected[j].Name })
+
+	for _, concurrency := range []int{0, 1, 4, 64} {
+		s, err := NewFilePathSubjecter(root, WithConcurrency(concurrency)).Subjects()
+		assert.NoError(err)
+		assert.Equal(expected, s, "concurrency %d", concurrency)
+	}
+}
+
+func TestSubjectsDigestAlgorithms(t *testing.T) {
+	assert := assert.New(t)
+
+	root := t.TempDir()
+	assert.NoError(os.WriteFile(path.Join(root, "hello.txt"), []byte("hello world\n"), 0644))
+
+	s, err := NewFilePathSubjecter(root, WithDigestAlgorithms(digest.SHA512, digest.GitoidBlobSHA1)).Subjects()
+	assert.NoError(err)
+	if assert.Len(s, 1) {
+		assert.Equal(DigestSet{
+			digest.SHA512:         "db3974a97f2407b7cae1ae637c0030687a11913274d578492558e39c16c017de84eacdc8c62fe34ee4e12b4b1428817f09b6a2760c3f8a664ceae94d2434a593",
+			digest.GitoidBlobSHA1: "3b18e512dba79e4c8300dd08aeb37f8e728b8dad",
+		}, s[0].Digest)
+	}
+
+	_, err = NewFilePathSubjecter(root, WithDigestAlgorithms("md5")).Subjects()
+	assert.ErrorContains(err, `unsupported digest algorithm "md5"`)
+}
+
+func TestSubjectsUnreadableFile(t *testing.T) {
+	if os.Getuid() == 0 {
+		t.Skip("file permissions are not enforced for root")
+	}
+	assert := assert.New(t)
+
+	root := t.TempDir()
+	assert.NoError(os.WriteFile(path.Join(root, "readable"), []byte("readable"), 0644))
+	assert.NoError(os.WriteFile(path.Join(root, "unreadable"), []byte("unreadable"), 0000))
+
+	s, err := NewFilePathSubjecter(root).Subjects()
+	assert.ErrorIs(err, os.ErrPermission)
+	assert.Nil(s)
+}
+
+func TestShaSum256HexEncoded(t *testing.T) {
+	assert.Equal(t, digest.SHA256Hex([]byte("salsa")), ShaSum256HexEncoded([]byte("salsa")))
 }
 
 func assertSubject(assert *assert.Assertions, subject []Subject, binaryName, binaryPath string) {
@@ -57,6 +214,6 @@ func assertSubject(assert *assert.Assertions, subject []Subject, binaryName, bin
 		return
 	}
 
-	shaHex := ShaSum256HexEncoded(binary)
+	shaHex := digest.SHA256Hex(binary)
 	assert.Contains(subject, Subject{Name: binaryName, Digest: DigestSet{"sha256": shaHex}})
 }
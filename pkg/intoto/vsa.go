@@ -0,0 +1,112 @@
+package intoto
+
+import (
+	"time"
+)
+
+const (
+	// VerificationSummaryPredicateType the predicate type of a SLSA Verification Summary Attestation
+	VerificationSummaryPredicateType = "https://slsa.dev/verification_summary/v1"
+	// VerificationResultPassed the artifact passed verification
+	VerificationResultPassed = "PASSED"
+	// VerificationResultFailed the artifact failed verification
+	VerificationResultFailed = "FAILED"
+	// SLSABuildLevel1 the artifact has provenance showing how it was built
+	SLSABuildLevel1 = "SLSA_BUILD_LEVEL_1"
+	// SLSABuildLevel2 the artifact has signed provenance generated by a hosted build platform
+	SLSABuildLevel2 = "SLSA_BUILD_LEVEL_2"
+	// SLSABuildLevel3 the artifact has provenance generated by a hardened build platform
+	SLSABuildLevel3 = "SLSA_BUILD_LEVEL_3"
+)
+
+// VerificationSummaryStatement builds a in-toto statement with predicate type https://slsa.dev/verification_summary/v1
+//
+// The verification result defaults to PASSED and the time verified to the current time.
+func VerificationSummaryStatement(opts ...StatementOption) *Statement {
+	stmt := &Statement{
+		Type:          StatementTypeV1,
+		PredicateType: VerificationSummaryPredicateType,
+		VerificationSummary: &VerificationSummary{
+			TimeVerified:       time.Now().UTC().Format(time.RFC3339),
+			VerificationResult: VerificationResultPassed,
+			SlsaVersion:        "1.0",
+		},
+	}
+	for _, opt := range opts {
+		opt(stmt)
+	}
+	return stmt
+}
+
+// WithVerifier sets the identity and version(s) of the verifier
+func WithVerifier(id string, version map[string]string) StatementOption {
+	return func(s *Statement) {
+		verificationSummary(s).Verifier = Verifier{ID: id, Version: version}
+	}
+}
+
+// WithVerifiedLevels adds the SLSA levels the artifact was verified at, e.g. SLSA_BUILD_LEVEL_2
+func WithVerifiedLevels(levels ...string) StatementOption {
+	return func(s *Statement) {
+		vsa := verificationSummary(s)
+		vsa.VerifiedLevels = append(vsa.VerifiedLevels, levels...)
+	}
+}
+
+// WithInputAttestations adds the attestations that were used to perform the verification
+func WithInputAttestations(attestations []ResourceDescriptor) StatementOption {
+	return func(s *Statement) {
+		vsa := verificationSummary(s)
+		vsa.InputAttestations = append(vsa.InputAttestations, attestations...)
+	}
+}
+
+// WithPolicy sets the policy the artifact was verified against
+func WithPolicy(uri string, digest DigestSet) StatementOption {
+	return func(s *Statement) {
+		verificationSummary(s).Policy = &ResourceDescriptor{URI: uri, Digest: digest}
+	}
+}
+
+// WithResourceURI sets the uri identifying the resource the verification was performed for
+func WithResourceURI(uri string) StatementOption {
+	return func(s *Statement) {
+		verificationSummary(s).ResourceURI = uri
+	}
+}
+
+// WithVerificationResult sets the verification result, either PASSED or FAILED
+func WithVerificationResult(result string) StatementOption {
+	return func(s *Statement) {
+		verificationSummary(s).VerificationResult = result
+	}
+}
+
+// verificationSummary returns the verification summary of the statement, initializing it when not set
+func verificationSummary(s *Statement) *VerificationSummary {
+	if s.VerificationSummary == nil {
+		s.VerificationSummary = &VerificationSummary{}
+	}
+	return s.VerificationSummary
+}
+
+// VerificationSummary The SLSA Verification Summary Attestation predicate.
+//
+// https://slsa.dev/spec/v1.0/verification_summary
+type VerificationSummary struct {
+	Verifier           Verifier             `json:"verifier"`
+	TimeVerified       string               `json:"timeVerified"`
+	ResourceURI        string               `json:"resourceUri"`
+	Policy             *ResourceDescriptor  `json:"policy,omitempty"`
+	InputAttestations  []ResourceDescriptor `json:"inputAttestations,omitempty"`
+	VerificationResult string               `json:"verificationResult"`
+	VerifiedLevels     []string             `json:"verifiedLevels"`
+	DependencyLevels   map[string]int       `json:"dependencyLevels,omitempty"`
+	SlsaVersion        string               `json:"slsaVersion,omitempty"`
+}
+
+// Verifier Identifies the entity that performed the verification.
+type Verifier struct {
+	ID      string            `json:"id"`
+	Version map[string]string `json:"version,omitempty"`
+}
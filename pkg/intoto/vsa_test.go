@@ -0,0 +1,87 @@
+package intoto
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerificationSummaryStatement(t *testing.T) {
+	assert := assert.New(t)
+
+	subjects := []Subject{{Name: "salty.tar.gz", Digest: DigestSet{"sha256": "f5e3c2a7"}}}
+	attestations := []ResourceDescriptor{{URI: "provenance.json", Digest: DigestSet{"sha256": "abc"}}}
+
+	stmt := VerificationSummaryStatement(
+		WithSubject(subjects),
+		WithVerifier("https://example.com/verifier", map[string]string{"slsa-provenance": "v0.8.0"}),
+		WithVerifiedLevels(SLSABuildLevel1, SLSABuildLevel2),
+		WithInputAttestations(attestations),
+		WithPolicy("https://example.com/policy.yaml", DigestSet{"sha256": "def"}),
+		WithResourceURI("pkg:generic/salty.tar.gz"),
+	)
+
+	assert.Equal(StatementTypeV1, stmt.Type)
+	assert.Equal(VerificationSummaryPredicateType, stmt.PredicateType)
+	assert.Equal(subjects, stmt.Subject)
+
+	vsa := stmt.VerificationSummary
+	assert.Equal("https://example.com/verifier", vsa.Verifier.ID)
+	assert.Equal("v0.8.0", vsa.Verifier.Version["slsa-provenance"])
+	assert.Equal([]string{SLSABuildLevel1, SLSABuildLevel2}, vsa.VerifiedLevels)
+	assert.Equal(attestations, vsa.InputAttestations)
+	assert.Equal(&ResourceDescriptor{URI: "https://example.com/policy.yaml", Digest: DigestSet{"sha256": "def"}}, vsa.Policy)
+	assert.Equal("pkg:generic/salty.tar.gz", vsa.ResourceURI)
+	assert.Equal(VerificationResultPassed, vsa.VerificationResult)
+	assert.NotEmpty(vsa.TimeVerified)
+	assert.Equal("1.0", vsa.SlsaVersion)
+
+	stmt = VerificationSummaryStatement(WithVerificationResult(VerificationResultFailed))
+	assert.Equal(VerificationResultFailed, stmt.VerificationSummary.VerificationResult)
+}
+
+func TestVerificationSummaryOptionsWithoutSummary(t *testing.T) {
+	assert := assert.New(t)
+
+	stmt := &Statement{}
+	assert.NotPanics(func() {
+		for _, opt := range []StatementOption{
+			WithVerifier("https://example.com/verifier", nil),
+			WithVerifiedLevels(SLSABuildLevel1),
+			WithInputAttestations([]ResourceDescriptor{{URI: "provenance.json"}}),
+			WithPolicy("https://example.com/policy.yaml", nil),
+			WithResourceURI("pkg:generic/salty.tar.gz"),
+			WithVerificationResult(VerificationResultFailed),
+		} {
+			opt(stmt)
+		}
+	})
+	if assert.NotNil(stmt.VerificationSummary) {
+		assert.Equal([]string{SLSABuildLevel1}, stmt.VerificationSummary.VerifiedLevels)
+		assert.Equal(VerificationResultFailed, stmt.VerificationSummary.VerificationResult)
+	}
+}
+
+func TestVerificationSummaryJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	stmt := VerificationSummaryStatement(
+		WithVerifier("https://example.com/verifier", nil),
+		WithVerifiedLevels(SLSABuildLevel2),
+		WithResourceURI("ghcr.io/philips-labs/slsa-provenance:v0.8.0"),
+	)
+
+	b, err := json.Marshal(stmt)
+	assert.NoError(err)
+	assert.Contains(string(b), `"predicateType":"https://slsa.dev/verification_summary/v1"`)
+	assert.Contains(string(b), `"verifiedLevels":["SLSA_BUILD_LEVEL_2"]`)
+	assert.Contains(string(b), `"resourceUri":"ghcr.io/philips-labs/slsa-provenance:v0.8.0"`)
+	assert.NotContains(string(b), `"builder"`)
+	assert.NotContains(string(b), `"policy"`)
+
+	var actual Statement
+	assert.NoError(json.Unmarshal(b, &actual))
+	assert.Equal(stmt.VerificationSummary, actual.VerificationSummary)
+	assert.Nil(actual.PredicateV1)
+}
@@ -0,0 +1,194 @@
+package oci
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+)
+
+const (
+	// AttachLayoutTag attaches attestations using the cosign compatible sha256-<digest>.att tag
+	AttachLayoutTag = "tag"
+	// AttachLayoutReferrers attaches attestations using the OCI 1.1 referrers API, falling back
+	// to the referrers tag schema for registries not supporting the referrers API
+	AttachLayoutReferrers = "referrers"
+
+	// InTotoArtifactType the artifactType of attestations attached using the referrers API
+	InTotoArtifactType = "application/vnd.in-toto+json"
+	// DSSEMediaType the media type of the layer holding the DSSE envelope
+	DSSEMediaType types.MediaType = "application/vnd.dsse.envelope.v1+json"
+	// PredicateTypeAnnotation the layer annotation holding the predicate type of the attestation
+	PredicateTypeAnnotation = "predicateType"
+
+	// emptyConfigMediaType the media type of the empty config of an OCI artifact manifest
+	emptyConfigMediaType types.MediaType = "application/vnd.oci.empty.v1+json"
+)
+
+// AttachEnvelope pushes the DSSE envelope to the registry as an attestation of the image digest reference
+// (e.g. ghcr.io/owner/app@sha256:...) using the given layout. It returns the reference of the pushed attestation.
+func AttachEnvelope(subject string, env *intoto.Envelope, layout string, options ...crane.Option) (string, error) {
+	o := crane.GetOptions(options...)
+	ref, err := name.NewDigest(subject, o.Name...)
+	if err != nil {
+		return "", fmt.Errorf("failed to attach attestation, %s is not a digest reference: %w", subject, err)
+	}
+
+	stmt, err := env.Statement()
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(env)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal envelope: %w", err)
+	}
+	layer := static.NewLayer(payload, DSSEMediaType)
+	annotations := map[string]string{PredicateTypeAnnotation: stmt.PredicateType}
+
+	switch layout {
+	case AttachLayoutTag:
+		return attachTag(ref, layer, annotations, o.Remote)
+	case AttachLayoutReferrers:
+		return attachReferrer(ref, layer, annotations, o.Remote)
+	default:
+		return "", fmt.Errorf("unsupported attach layout %q, supported layouts: %s, %s", layout, AttachLayoutTag, AttachLayoutReferrers)
+	}
+}
+
+// AttestationTag the cosign compatible tag holding the attestations of the image digest, e.g. sha256-<hex>.att
+func AttestationTag(ref name.Digest) name.Tag {
+	return ref.Context().Tag(strings.Replace(ref.DigestStr(), ":", "-", 1) + ".att")
+}
+
+// attachTag appends the envelope layer to the image tagged sha256-<digest>.att, creating it when it doesn't exist yet.
+func attachTag(ref name.Digest, layer v1.Layer, annotations map[string]string, options []remote.Option) (string, error) {
+	tag := AttestationTag(ref)
+
+	base, err := remote.Image(tag, options...)
+	if err != nil {
+		var terr *transport.Error
+		if !errors.As(err, &terr) || terr.StatusCode != http.StatusNotFound {
+			return "", fmt.Errorf("failed to fetch attestations %s: %w", tag, err)
+		}
+		base = mutate.ConfigMediaType(mutate.MediaType(empty.Image, types.OCIManifestSchema1), types.OCIConfigJSON)
+	}
+
+	layerDigest, err := layer.Digest()
+	if err != nil {
+		return "", err
+	}
+	layers, err := base.Layers()
+	if err != nil {
+		return "", fmt.Errorf("failed to read attestations %s: %w", tag, err)
+	}
+	for _, l := range layers {
+		if d, err := l.Digest(); err == nil && d == layerDigest {
+			return tag.String(), nil
+		}
+	}
+
+	img, err := mutate.Append(base, mutate.Addendum{Layer: layer, Annotations: annotations})
+	if err != nil {
+		return "", err
+	}
+	if err := remote.Write(tag, img, options...); err != nil {
+		return "", fmt.Errorf("failed to push attestations %s: %w", tag, err)
+	}
+
+	return tag.String(), nil
+}
+
+// attachReferrer pushes an OCI artifact manifest with the envelope layer referring to the image digest.
+func attachReferrer(ref name.Digest, layer v1.Layer, annotations map[string]string, options []remote.Option) (string, error) {
+	subject, err := remote.Head(ref, options...)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch subject %s: %w", ref, err)
+	}
+
+	config := static.NewLayer([]byte("{}"), emptyConfigMediaType)
+	for _, l := range []v1.Layer{config, layer} {
+		if err := remote.WriteLayer(ref.Context(), l, options...); err != nil {
+			return "", fmt.Errorf("failed to push attestation blob: %w", err)
+		}
+	}
+
+	configDesc, err := layerDescriptor(config, nil)
+	if err != nil {
+		return "", err
+	}
+	layerDesc, err := layerDescriptor(layer, annotations)
+	if err != nil {
+		return "", err
+	}
+
+	manifest, err := json.Marshal(v1.Manifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIManifestSchema1,
+		ArtifactType:  InTotoArtifactType,
+		Config:        configDesc,
+		Layers:        []v1.Descriptor{layerDesc},
+		Subject: &v1.Descriptor{
+			MediaType: subject.MediaType,
+			Digest:    subject.Digest,
+			Size:      subject.Size,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal attestation manifest: %w", err)
+	}
+
+	manifestDigest, _, err := v1.SHA256(bytes.NewReader(manifest))
+	if err != nil {
+		return "", err
+	}
+	attestation := ref.Context().Digest(manifestDigest.String())
+	if err := remote.Put(attestation, rawManifest{manifest: manifest, mediaType: types.OCIManifestSchema1}, options...); err != nil {
+		return "", fmt.Errorf("failed to push attestation %s: %w", attestation, err)
+	}
+
+	return attestation.String(), nil
+}
+
+func layerDescriptor(l v1.Layer, annotations map[string]string) (v1.Descriptor, error) {
+	d, err := l.Digest()
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	size, err := l.Size()
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	mt, err := l.MediaType()
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	return v1.Descriptor{MediaType: mt, Digest: d, Size: size, Annotations: annotations}, nil
+}
+
+// rawManifest implements remote.Taggable for a serialized manifest
+type rawManifest struct {
+	manifest  []byte
+	mediaType types.MediaType
+}
+
+func (m rawManifest) RawManifest() ([]byte, error) {
+	return m.manifest, nil
+}
+
+func (m rawManifest) MediaType() (types.MediaType, error) {
+	return m.mediaType, nil
+}
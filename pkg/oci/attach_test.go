@@ -0,0 +1,155 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+)
+
+func testEnvelope(t *testing.T, subject string) *intoto.Envelope {
+	env, err := intoto.NewEnvelope(intoto.SLSAProvenanceStatement(
+		intoto.WithSubject([]intoto.Subject{{Name: subject, Digest: intoto.DigestSet{"sha256": "abc"}}}),
+	))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return env
+}
+
+func TestAttachEnvelopeTag(t *testing.T) {
+	assert := assert.New(t)
+
+	repo, img := pushRandomImage(t, "v0.1.0")
+	imgDigest, err := img.Digest()
+	assert.NoError(err)
+	subject := fmt.Sprintf("%s@%s", repo, imgDigest)
+
+	env := testEnvelope(t, subject)
+	ref, err := AttachEnvelope(subject, env, AttachLayoutTag, crane.Insecure)
+	assert.NoError(err)
+	assert.Equal(fmt.Sprintf("%s:sha256-%s.att", repo, imgDigest.Hex), ref)
+
+	// attaching the same envelope again does not add another layer
+	_, err = AttachEnvelope(subject, env, AttachLayoutTag, crane.Insecure)
+	assert.NoError(err)
+	_, err = AttachEnvelope(subject, testEnvelope(t, "other"), AttachLayoutTag, crane.Insecure)
+	assert.NoError(err)
+
+	att, err := crane.Pull(ref, crane.Insecure)
+	if !assert.NoError(err) {
+		return
+	}
+	manifest, err := att.Manifest()
+	assert.NoError(err)
+	if assert.Len(manifest.Layers, 2) {
+		assert.Equal(DSSEMediaType, manifest.Layers[0].MediaType)
+		assert.Equal(map[string]string{PredicateTypeAnnotation: intoto.SlsaPredicateType}, manifest.Layers[0].Annotations)
+	}
+
+	layers, err := att.Layers()
+	assert.NoError(err)
+	assertEnvelopeLayer(assert, layers[0], env)
+}
+
+func TestAttachEnvelopeReferrers(t *testing.T) {
+	for _, referrersSupport := range []bool{true, false} {
+		t.Run(fmt.Sprintf("referrers API %t", referrersSupport), func(tt *testing.T) {
+			assert := assert.New(tt)
+
+			s := httptest.NewServer(registry.New(registry.WithReferrersSupport(referrersSupport)))
+			tt.Cleanup(s.Close)
+			repo := fmt.Sprintf("%s/philips-labs/slsa-provenance", strings.TrimPrefix(s.URL, "http://"))
+			img, err := random.Image(1024, 1)
+			assert.NoError(err)
+			assert.NoError(crane.Push(img, repo+":v0.1.0", crane.Insecure))
+			imgDigest, err := img.Digest()
+			assert.NoError(err)
+			subject := fmt.Sprintf("%s@%s", repo, imgDigest)
+
+			env := testEnvelope(tt, subject)
+			ref, err := AttachEnvelope(subject, env, AttachLayoutReferrers, crane.Insecure)
+			if !assert.NoError(err) {
+				return
+			}
+			assert.True(strings.HasPrefix(ref, repo+"@sha256:"))
+
+			d, err := name.NewDigest(subject, name.Insecure)
+			assert.NoError(err)
+			idx, err := remote.Referrers(d)
+			if !assert.NoError(err) {
+				return
+			}
+			referrers, err := idx.IndexManifest()
+			assert.NoError(err)
+			if assert.Len(referrers.Manifests, 1) {
+				assert.Equal(ref, fmt.Sprintf("%s@%s", repo, referrers.Manifests[0].Digest))
+			}
+
+			_, err = crane.Manifest(fmt.Sprintf("%s:sha256-%s", repo, imgDigest.Hex), crane.Insecure)
+			if referrersSupport {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+			}
+
+			att, err := crane.Pull(ref, crane.Insecure)
+			if !assert.NoError(err) {
+				return
+			}
+			manifest, err := att.Manifest()
+			assert.NoError(err)
+			assert.Equal(InTotoArtifactType, manifest.ArtifactType)
+			assert.Equal(imgDigest, manifest.Subject.Digest)
+			layers, err := att.Layers()
+			assert.NoError(err)
+			if assert.Len(layers, 1) {
+				assertEnvelopeLayer(assert, layers[0], env)
+			}
+		})
+	}
+}
+
+func TestAttachEnvelopeErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	repo, img := pushRandomImage(t, "v0.1.0")
+	imgDigest, err := img.Digest()
+	assert.NoError(err)
+	subject := fmt.Sprintf("%s@%s", repo, imgDigest)
+	env := testEnvelope(t, subject)
+
+	_, err = AttachEnvelope(subject, env, "sidecar", crane.Insecure)
+	assert.EqualError(err, `unsupported attach layout "sidecar", supported layouts: tag, referrers`)
+
+	_, err = AttachEnvelope(repo+":v0.1.0", env, AttachLayoutTag, crane.Insecure)
+	assert.ErrorContains(err, fmt.Sprintf("failed to attach attestation, %s:v0.1.0 is not a digest reference", repo))
+
+	_, err = AttachEnvelope(subject, &intoto.Envelope{PayloadType: "text/plain"}, AttachLayoutTag, crane.Insecure)
+	assert.EqualError(err, `unsupported payloadType "text/plain", expected "application/vnd.in-toto+json"`)
+}
+
+func assertEnvelopeLayer(assert *assert.Assertions, layer interface {
+	Uncompressed() (io.ReadCloser, error)
+}, expected *intoto.Envelope) {
+	rc, err := layer.Uncompressed()
+	if !assert.NoError(err) {
+		return
+	}
+	defer rc.Close()
+
+	var env intoto.Envelope
+	assert.NoError(json.NewDecoder(rc).Decode(&env))
+	assert.Equal(*expected, env)
+}
@@ -0,0 +1,149 @@
+package oci
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+)
+
+// Attestation a DSSE envelope attached to an image
+type Attestation struct {
+	// Layout the layout the attestation was found in, AttachLayoutTag or AttachLayoutReferrers
+	Layout string `json:"layout"`
+	// Ref the reference of the manifest holding the attestation
+	Ref       string            `json:"ref"`
+	Envelope  *intoto.Envelope  `json:"envelope"`
+	Statement *intoto.Statement `json:"-"`
+}
+
+// FetchAttestations retrieves the DSSE envelopes attached to the image, using both the cosign compatible
+// sha256-<digest>.att tag and the OCI 1.1 referrers API (or referrers tag schema). Tag references are
+// resolved to the digest they point to.
+func FetchAttestations(image string, options ...crane.Option) ([]Attestation, error) {
+	o := crane.GetOptions(options...)
+	ref, err := name.ParseReference(image, o.Name...)
+	if err != nil {
+		return nil, err
+	}
+
+	d, ok := ref.(name.Digest)
+	if !ok {
+		imageDigest, err := crane.Digest(image, options...)
+		if err != nil {
+			return nil, err
+		}
+		d = ref.Context().Digest(imageDigest)
+	}
+
+	attestations, err := tagAttestations(d, o.Remote)
+	if err != nil {
+		return nil, err
+	}
+	referrers, err := referrerAttestations(d, o.Remote)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(attestations, referrers...), nil
+}
+
+func tagAttestations(ref name.Digest, options []remote.Option) ([]Attestation, error) {
+	tag := AttestationTag(ref)
+	img, err := remote.Image(tag, options...)
+	if err != nil {
+		var terr *transport.Error
+		if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch attestations %s: %w", tag, err)
+	}
+
+	return imageAttestations(img, AttachLayoutTag, tag.String())
+}
+
+func referrerAttestations(ref name.Digest, options []remote.Option) ([]Attestation, error) {
+	idx, err := remote.Referrers(ref, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch referrers of %s: %w", ref, err)
+	}
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read referrers of %s: %w", ref, err)
+	}
+
+	var attestations []Attestation
+	for _, desc := range manifest.Manifests {
+		// NOTE: registries deriving the artifactType from the config media type report the empty config media type.
+		if desc.ArtifactType != InTotoArtifactType && desc.ArtifactType != string(emptyConfigMediaType) {
+			continue
+		}
+
+		referrer := ref.Context().Digest(desc.Digest.String())
+		img, err := remote.Image(referrer, options...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch attestation %s: %w", referrer, err)
+		}
+		a, err := imageAttestations(img, AttachLayoutReferrers, referrer.String())
+		if err != nil {
+			return nil, err
+		}
+		attestations = append(attestations, a...)
+	}
+
+	return attestations, nil
+}
+
+// imageAttestations decodes the DSSE envelope layers of the image
+func imageAttestations(img v1.Image, layout, ref string) ([]Attestation, error) {
+	manifest, err := img.Manifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attestation %s: %w", ref, err)
+	}
+
+	var attestations []Attestation
+	for _, desc := range manifest.Layers {
+		if desc.MediaType != DSSEMediaType {
+			continue
+		}
+
+		env, stmt, err := layerEnvelope(img, desc.Digest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode attestation %s layer %s: %w", ref, desc.Digest, err)
+		}
+		attestations = append(attestations, Attestation{Layout: layout, Ref: ref, Envelope: env, Statement: stmt})
+	}
+
+	return attestations, nil
+}
+
+func layerEnvelope(img v1.Image, h v1.Hash) (*intoto.Envelope, *intoto.Statement, error) {
+	layer, err := img.LayerByDigest(h)
+	if err != nil {
+		return nil, nil, err
+	}
+	rc, err := layer.Uncompressed()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rc.Close()
+
+	var env intoto.Envelope
+	if err := json.NewDecoder(rc).Decode(&env); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal envelope json: %w", err)
+	}
+	stmt, err := env.Statement()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &env, stmt, nil
+}
@@ -0,0 +1,73 @@
+package oci
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+)
+
+func TestFetchAttestations(t *testing.T) {
+	for _, referrersSupport := range []bool{true, false} {
+		t.Run(fmt.Sprintf("referrers API %t", referrersSupport), func(tt *testing.T) {
+			assert := assert.New(tt)
+
+			s := httptest.NewServer(registry.New(registry.WithReferrersSupport(referrersSupport)))
+			tt.Cleanup(s.Close)
+			repo := fmt.Sprintf("%s/philips-labs/slsa-provenance", strings.TrimPrefix(s.URL, "http://"))
+			img, err := random.Image(1024, 1)
+			assert.NoError(err)
+			assert.NoError(crane.Push(img, repo+":v0.1.0", crane.Insecure))
+			imgDigest, err := img.Digest()
+			assert.NoError(err)
+			subject := fmt.Sprintf("%s@%s", repo, imgDigest)
+
+			attestations, err := FetchAttestations(repo+":v0.1.0", crane.Insecure)
+			assert.NoError(err)
+			assert.Empty(attestations)
+
+			tagEnv := testEnvelope(tt, "tag")
+			tagRef, err := AttachEnvelope(subject, tagEnv, AttachLayoutTag, crane.Insecure)
+			assert.NoError(err)
+			referrerEnv := testEnvelope(tt, "referrer")
+			referrerRef, err := AttachEnvelope(subject, referrerEnv, AttachLayoutReferrers, crane.Insecure)
+			assert.NoError(err)
+
+			for _, image := range []string{repo + ":v0.1.0", subject} {
+				attestations, err = FetchAttestations(image, crane.Insecure)
+				assert.NoError(err)
+				if assert.Len(attestations, 2) {
+					assert.Equal(AttachLayoutTag, attestations[0].Layout)
+					assert.Equal(tagRef, attestations[0].Ref)
+					assert.Equal(tagEnv, attestations[0].Envelope)
+					assert.Equal(intoto.SlsaPredicateType, attestations[0].Statement.PredicateType)
+					assert.Equal("tag", attestations[0].Statement.Subject[0].Name)
+
+					assert.Equal(AttachLayoutReferrers, attestations[1].Layout)
+					assert.Equal(referrerRef, attestations[1].Ref)
+					assert.Equal(referrerEnv, attestations[1].Envelope)
+					assert.Equal("referrer", attestations[1].Statement.Subject[0].Name)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchAttestationsErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	repo, _ := pushRandomImage(t, "v0.1.0")
+
+	_, err := FetchAttestations(repo+":non-existing", crane.Insecure)
+	assert.ErrorContains(err, "MANIFEST_UNKNOWN")
+
+	_, err = FetchAttestations("", crane.Insecure)
+	assert.EqualError(err, "could not parse reference: ")
+}
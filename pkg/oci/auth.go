@@ -24,7 +24,7 @@ func WithDefaultClientOptions(ctx context.Context, k8sKeychain, allowInsecure bo
 		kc := authn.NewMultiKeychain(
 			authn.DefaultKeychain,
 			google.Keychain,
-			authn.NewKeychainFromHelper(ecr.ECRHelper{ClientFactory: api.DefaultClientFactory{}}),
+			authn.NewKeychainFromHelper(ecr.NewECRHelper(ecr.WithClientFactory(api.DefaultClientFactory{}))),
 			authn.NewKeychainFromHelper(credhelper.NewACRCredentialsHelper()),
 		)
 		opts = append(opts, crane.WithAuthFromKeychain(kc))
@@ -0,0 +1,175 @@
+package oci
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/digest"
+)
+
+// RefNameAnnotation the OCI image layout annotation holding the tag or reference of an image
+const RefNameAnnotation = "org.opencontainers.image.ref.name"
+
+// NewLayoutSubjecter computes the digests of the tags from an OCI image layout directory, without a registry.
+// Tags are resolved using the org.opencontainers.image.ref.name annotation in the index.json of the layout.
+// A layout holding a single image resolves all tags to that image.
+func NewLayoutSubjecter(path, repo, digest string, tags []string) *ContainerSubjecter {
+	return newContainerSubjecter(layoutSource{path: path}, repo, digest, tags)
+}
+
+// NewTarballSubjecter computes the digests of the tags from a docker save tarball, without a registry.
+// Tags are resolved using the RepoTags in the manifest.json of the tarball. A tarball holding a single image
+// resolves all tags to that image. The digests match the image as pushed using go-containerregistry (e.g. crane push).
+func NewTarballSubjecter(path, repo, digest string, tags []string) *ContainerSubjecter {
+	return newContainerSubjecter(tarballSource{path: path}, repo, digest, tags)
+}
+
+// layoutSource retrieves the image manifests from an OCI image layout directory
+type layoutSource struct {
+	path string
+}
+
+func (s layoutSource) digest(ref string) (string, error) {
+	raw, _, err := s.manifest(ref)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%s", digest.SHA256, digest.SHA256Hex(raw)), nil
+}
+
+func (s layoutSource) manifest(ref string) ([]byte, types.MediaType, error) {
+	r, err := name.ParseReference(ref)
+	if err != nil {
+		return nil, "", err
+	}
+
+	p, err := layout.FromPath(s.path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read OCI image layout %s: %w", s.path, err)
+	}
+	idx, err := p.ImageIndex()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read OCI image layout %s: %w", s.path, err)
+	}
+
+	var desc *v1.Descriptor
+	switch r := r.(type) {
+	case name.Digest:
+		desc, err = s.findDigest(idx, r.DigestStr())
+	case name.Tag:
+		desc, err = s.findTag(idx, r)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	raw, err := p.Bytes(desc.Digest)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read manifest %s from OCI image layout %s: %w", desc.Digest, s.path, err)
+	}
+	return raw, desc.MediaType, nil
+}
+
+// findTag finds the descriptor annotated with the tag, either the tag itself or the full reference
+func (s layoutSource) findTag(idx v1.ImageIndex, tag name.Tag) (*v1.Descriptor, error) {
+	index, err := idx.IndexManifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OCI image layout %s: %w", s.path, err)
+	}
+
+	for i, m := range index.Manifests {
+		refName := m.Annotations[RefNameAnnotation]
+		if refName == "" {
+			continue
+		}
+		if refName == tag.TagStr() {
+			return &index.Manifests[i], nil
+		}
+		if t, err := name.NewTag(refName); err == nil && t.Name() == tag.Name() {
+			return &index.Manifests[i], nil
+		}
+	}
+
+	if len(index.Manifests) == 1 {
+		return &index.Manifests[0], nil
+	}
+	return nil, fmt.Errorf("tag %s not found in OCI image layout %s", tag.TagStr(), s.path)
+}
+
+// findDigest finds the descriptor of the digest in the index and its nested indexes
+func (s layoutSource) findDigest(idx v1.ImageIndex, d string) (*v1.Descriptor, error) {
+	index, err := idx.IndexManifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OCI image layout %s: %w", s.path, err)
+	}
+	for i, m := range index.Manifests {
+		if m.Digest.String() == d {
+			return &index.Manifests[i], nil
+		}
+	}
+
+	for _, m := range index.Manifests {
+		if !m.MediaType.IsIndex() {
+			continue
+		}
+		child, err := idx.ImageIndex(m.Digest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image index %s from OCI image layout %s: %w", m.Digest, s.path, err)
+		}
+		if desc, err := s.findDigest(child, d); err == nil {
+			return desc, nil
+		}
+	}
+
+	return nil, fmt.Errorf("digest %s not found in OCI image layout %s", d, s.path)
+}
+
+// tarballSource retrieves the image manifests from a docker save tarball
+type tarballSource struct {
+	path string
+}
+
+func (s tarballSource) digest(ref string) (string, error) {
+	raw, _, err := s.manifest(ref)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%s", digest.SHA256, digest.SHA256Hex(raw)), nil
+}
+
+func (s tarballSource) manifest(ref string) ([]byte, types.MediaType, error) {
+	tag, err := name.NewTag(ref)
+	if err != nil {
+		return nil, "", fmt.Errorf("digest reference %s is not supported for docker tarballs: %w", ref, err)
+	}
+
+	m, err := tarball.LoadManifest(func() (io.ReadCloser, error) { return os.Open(s.path) })
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read docker tarball %s: %w", s.path, err)
+	}
+	selected := &tag
+	if len(m) == 1 {
+		selected = nil
+	}
+
+	img, err := tarball.ImageFromPath(s.path, selected)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read docker tarball %s: %w", s.path, err)
+	}
+	raw, err := img.RawManifest()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read docker tarball %s: %w", s.path, err)
+	}
+	mediaType, err := img.MediaType()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read docker tarball %s: %w", s.path, err)
+	}
+	return raw, mediaType, nil
+}
@@ -0,0 +1,148 @@
+package oci
+
+import (
+	"fmt"
+	"path"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/digest"
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+)
+
+const localRepo = "ghcr.io/philips-labs/slsa-provenance"
+
+func TestLayoutSubjecter(t *testing.T) {
+	assert := assert.New(t)
+
+	img, err := random.Image(1024, 1)
+	assert.NoError(err)
+	amd64, err := random.Image(1024, 1)
+	assert.NoError(err)
+	arm64, err := random.Image(1024, 1)
+	assert.NoError(err)
+	idx := mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{Add: amd64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}}},
+		mutate.IndexAddendum{Add: arm64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}}},
+	)
+
+	dir := t.TempDir()
+	p, err := layout.Write(dir, empty.Index)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.NoError(p.AppendImage(img, layout.WithAnnotations(map[string]string{RefNameAnnotation: "v0.1.0"})))
+	assert.NoError(p.AppendIndex(idx, layout.WithAnnotations(map[string]string{RefNameAnnotation: localRepo + ":v0.2.0"})))
+
+	imgDigest, err := img.Digest()
+	assert.NoError(err)
+	idxDigest, err := idx.Digest()
+	assert.NoError(err)
+	amd64Digest, err := amd64.Digest()
+	assert.NoError(err)
+	arm64Digest, err := arm64.Digest()
+	assert.NoError(err)
+
+	s, err := NewLayoutSubjecter(dir, localRepo, imgDigest.String(), []string{"v0.1.0"}).Subjects()
+	assert.NoError(err)
+	assert.Equal([]intoto.Subject{{Name: localRepo + ":v0.1.0", Digest: intoto.DigestSet{"sha256": imgDigest.Hex}}}, s)
+
+	s, err = NewLayoutSubjecter(dir, localRepo, "", []string{"v0.2.0"}).WithPlatformManifests().Subjects()
+	assert.NoError(err)
+	assert.Equal([]intoto.Subject{
+		{Name: localRepo + ":v0.2.0", Digest: intoto.DigestSet{"sha256": idxDigest.Hex}},
+		{Name: localRepo + "@" + amd64Digest.String(), Digest: intoto.DigestSet{"sha256": amd64Digest.Hex}, Annotations: map[string]string{PlatformAnnotation: "linux/amd64"}},
+		{Name: localRepo + "@" + arm64Digest.String(), Digest: intoto.DigestSet{"sha256": arm64Digest.Hex}, Annotations: map[string]string{PlatformAnnotation: "linux/arm64"}},
+	}, s)
+
+	s, err = NewLayoutSubjecter(dir, localRepo, "", []string{"v0.2.0"}).
+		WithPlatformManifests(v1.Platform{OS: "linux", Architecture: "arm64"}).
+		WithDigestAlgorithms(digest.SHA256, digest.SHA512).
+		Subjects()
+	assert.NoError(err)
+	if assert.Len(s, 2) {
+		manifest, err := arm64.RawManifest()
+		assert.NoError(err)
+		expected, err := digest.FromBytes(manifest, digest.SHA256, digest.SHA512)
+		assert.NoError(err)
+		assert.Equal(intoto.DigestSet(expected), s[1].Digest)
+	}
+
+	_, err = NewLayoutSubjecter(dir, localRepo, "", []string{"v0.3.0"}).Subjects()
+	assert.EqualError(err, fmt.Sprintf("tag v0.3.0 not found in OCI image layout %s", dir))
+
+	_, err = NewLayoutSubjecter(dir, localRepo, "sha256:284b471a878add368bf02a7935fa099024576c029491bcefaeb87f81efa093a4", []string{"v0.1.0"}).Subjects()
+	assert.EqualError(err, fmt.Sprintf("did not get expected digest sha256:284b471a878add368bf02a7935fa099024576c029491bcefaeb87f81efa093a4 for %s:v0.1.0 (got %s)", localRepo, imgDigest))
+
+	s, err = NewLayoutSubjecter(dir, localRepo+"@"+amd64Digest.String(), "", nil).Subjects()
+	assert.NoError(err)
+	assert.Equal([]intoto.Subject{{Name: localRepo + "@" + amd64Digest.String(), Digest: intoto.DigestSet{"sha256": amd64Digest.Hex}}}, s)
+
+	invalid := path.Join(t.TempDir(), "invalid")
+	_, err = NewLayoutSubjecter(invalid, localRepo, "", []string{"v0.1.0"}).Subjects()
+	assert.ErrorContains(err, fmt.Sprintf("failed to read OCI image layout %s: ", invalid))
+
+	single := t.TempDir()
+	_, err = layout.Write(single, mutate.AppendManifests(empty.Index, mutate.IndexAddendum{Add: img}))
+	assert.NoError(err)
+	s, err = NewLayoutSubjecter(single, localRepo, "", []string{"latest", "v0.1.0"}).Subjects()
+	assert.NoError(err)
+	assert.Equal([]intoto.Subject{
+		{Name: localRepo + ":latest", Digest: intoto.DigestSet{"sha256": imgDigest.Hex}},
+		{Name: localRepo + ":v0.1.0", Digest: intoto.DigestSet{"sha256": imgDigest.Hex}},
+	}, s)
+}
+
+func TestTarballSubjecter(t *testing.T) {
+	assert := assert.New(t)
+
+	img, err := random.Image(1024, 1)
+	assert.NoError(err)
+	other, err := random.Image(1024, 1)
+	assert.NoError(err)
+
+	dir := t.TempDir()
+	singleTarball := path.Join(dir, "single.tar")
+	tag, err := name.NewTag(localRepo + ":v0.1.0")
+	assert.NoError(err)
+	assert.NoError(tarball.WriteToFile(singleTarball, tag, img))
+
+	// push the image from the tarball to compare the digests
+	repo, _ := pushRandomImage(t)
+	tarballImg, err := tarball.ImageFromPath(singleTarball, nil)
+	assert.NoError(err)
+	assert.NoError(crane.Push(tarballImg, repo+":v0.1.0", crane.Insecure))
+	pushedDigest, err := crane.Digest(repo+":v0.1.0", crane.Insecure)
+	assert.NoError(err)
+
+	s, err := NewTarballSubjecter(singleTarball, localRepo, pushedDigest, []string{"v0.1.0"}).Subjects()
+	assert.NoError(err)
+	assert.Equal([]intoto.Subject{{Name: localRepo + ":v0.1.0", Digest: intoto.DigestSet{"sha256": pushedDigest[len("sha256:"):]}}}, s)
+
+	multiTarball := path.Join(dir, "multi.tar")
+	otherTag, err := name.NewTag(localRepo + ":v0.2.0")
+	assert.NoError(err)
+	assert.NoError(tarball.MultiWriteToFile(multiTarball, map[name.Tag]v1.Image{tag: img, otherTag: other}))
+
+	s, err = NewTarballSubjecter(multiTarball, localRepo, "", []string{"v0.1.0", "v0.2.0"}).Subjects()
+	assert.NoError(err)
+	if assert.Len(s, 2) {
+		assert.Equal(pushedDigest[len("sha256:"):], s[0].Digest["sha256"])
+		assert.NotEqual(s[0].Digest, s[1].Digest)
+	}
+
+	_, err = NewTarballSubjecter(multiTarball, localRepo, "", []string{"v0.3.0"}).Subjects()
+	assert.EqualError(err, fmt.Sprintf("failed to read docker tarball %s: tag %s:v0.3.0 not found in tarball", multiTarball, localRepo))
+
+	_, err = NewTarballSubjecter(path.Join(dir, "missing.tar"), localRepo, "", nil).Subjects()
+	assert.ErrorContains(err, fmt.Sprintf("failed to read docker tarball %s/missing.tar: ", dir))
+}
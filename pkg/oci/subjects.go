@@ -1,62 +1,237 @@
 package oci
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
+	"bytes"
 	"fmt"
 	"strings"
 
 	"github.com/google/go-containerregistry/pkg/crane"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
 
+	"github.com/philips-labs/slsa-provenance-action/pkg/digest"
 	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
 )
 
+// PlatformAnnotation the subject annotation holding the os/arch/variant of a platform manifest
+const PlatformAnnotation = "platform"
+
 // ContainerSubjecter implements Subjector to retrieve Subject from given container
 // if digest is given, it will also compare matches with the given digest
 type ContainerSubjecter struct {
-	options []crane.Option
-	repo    string
-	digest  string
-	tags    []string
+	source            manifestSource
+	repo              string
+	pinned            string
+	digest            string
+	tags              []string
+	algorithms        []string
+	platformManifests bool
+	platforms         []v1.Platform
 }
 
 // NewContainerSubjecter walks the docker tags to retrieve the digests.
 // If digest is non empty string it will be used to compare the rerieved digest
-// to match the given digest
+// to match the given digest.
+//
+// The repo can be pinned by digest (e.g. ghcr.io/owner/app@sha256:...). Without tags a single subject
+// is retrieved for the pinned digest, with tags the digests of the tags are compared to the pinned digest.
 func NewContainerSubjecter(repo, digest string, tags []string, options ...crane.Option) *ContainerSubjecter {
-	return &ContainerSubjecter{options, repo, digest, tags}
+	return newContainerSubjecter(registrySource{options: options}, repo, digest, tags)
+}
+
+func newContainerSubjecter(source manifestSource, repo, digest string, tags []string) *ContainerSubjecter {
+	c := &ContainerSubjecter{source: source, repo: repo, digest: digest, tags: tags}
+	if i := strings.LastIndex(repo, "@"); i >= 0 {
+		c.repo, c.pinned = repo[:i], repo[i+1:]
+	}
+	return c
+}
+
+// WithDigestAlgorithms sets the digest algorithms to hash the image manifests with, defaults to the registry digest (sha256)
+func (c *ContainerSubjecter) WithDigestAlgorithms(algorithms ...string) *ContainerSubjecter {
+	c.algorithms = algorithms
+	return c
 }
 
-// Subjects walks the file or directory at "root" and hashes all files.
+// WithPlatformManifests walks image indexes and manifest lists to add a subject per platform manifest.
+// If platforms are given, only the manifests satisfying one of the platforms are added.
+func (c *ContainerSubjecter) WithPlatformManifests(platforms ...v1.Platform) *ContainerSubjecter {
+	c.platformManifests = true
+	c.platforms = platforms
+	return c
+}
+
+// Subjects retrieves the digests of the tags, or of the pinned digest when no tags are given.
+// All tags not matching the expected digest are reported in a single error.
 func (c *ContainerSubjecter) Subjects() ([]intoto.Subject, error) {
-	subjects := make([]intoto.Subject, len(c.tags))
+	if err := digest.Validate(c.algorithms...); err != nil {
+		return nil, err
+	}
 
-	if c.tags == nil || len(c.tags) == 0 {
-		c.tags = []string{"latest"}
+	expected := c.digest
+	if c.pinned != "" {
+		if c.digest != "" && c.digest != c.pinned {
+			return nil, fmt.Errorf("digest %s does not match the pinned digest %s", c.digest, c.pinned)
+		}
+		expected = c.pinned
 	}
 
+	refs := make([]string, len(c.tags))
 	for i, t := range c.tags {
-		digest, err := crane.Digest(fmt.Sprintf("%s:%s", c.repo, t), c.options...)
+		refs[i] = fmt.Sprintf("%s:%s", c.repo, t)
+	}
+	if len(refs) == 0 {
+		if c.pinned != "" {
+			refs = []string{fmt.Sprintf("%s@%s", c.repo, c.pinned)}
+		} else {
+			refs = []string{fmt.Sprintf("%s:%s", c.repo, "latest")}
+		}
+	}
+
+	var (
+		subjects   []intoto.Subject
+		mismatches []string
+	)
+	seen := make(map[string]bool)
+
+	for _, ref := range refs {
+		digests, manifestDigest, err := c.digests(ref)
+		if err != nil {
+			return nil, err
+		}
+		if expected != "" && expected != manifestDigest {
+			mismatches = append(mismatches, fmt.Sprintf("%s (got %s)", ref, manifestDigest))
+			continue
+		}
+		subjects = append(subjects, intoto.Subject{
+			Name:   ref,
+			Digest: intoto.DigestSet(digests),
+		})
+
+		if !c.platformManifests || seen[manifestDigest] {
+			continue
+		}
+		seen[manifestDigest] = true
+
+		platformSubjects, err := c.platformSubjects(fmt.Sprintf("%s@%s", c.repo, manifestDigest))
 		if err != nil {
 			return nil, err
 		}
-		if c.digest != "" && c.digest != digest {
-			return nil, fmt.Errorf("did not get expected digest, got %s, expected %s", digest, c.digest)
+		subjects = append(subjects, platformSubjects...)
+	}
+
+	if len(mismatches) > 0 {
+		return nil, fmt.Errorf("did not get expected digest %s for %s", expected, strings.Join(mismatches, ", "))
+	}
+
+	return subjects, nil
+}
+
+// platformSubjects retrieves the platform manifests of the image index at ref. When ref is not an image index or
+// manifest list no subjects are returned. The subjects are named by digest and annotated with the platform.
+func (c *ContainerSubjecter) platformSubjects(ref string) ([]intoto.Subject, error) {
+	raw, mediaType, err := c.source.manifest(ref)
+	if err != nil {
+		return nil, err
+	}
+	if !mediaType.IsIndex() {
+		return nil, nil
+	}
+
+	manifest, err := v1.ParseIndexManifest(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image index %s: %w", ref, err)
+	}
+
+	var subjects []intoto.Subject
+	for _, m := range manifest.Manifests {
+		if m.Platform == nil || !c.matchesPlatform(*m.Platform) {
+			continue
 		}
-		digestParts := strings.Split(digest, ":")
-		subjects[i] = intoto.Subject{
-			Name:   fmt.Sprintf("%s:%s", c.repo, t),
-			Digest: intoto.DigestSet{digestParts[0]: digestParts[1]},
+
+		platformRef := fmt.Sprintf("%s@%s", c.repo, m.Digest)
+		digests := map[string]string{m.Digest.Algorithm: m.Digest.Hex}
+		if !c.registryDigestOnly() {
+			digests, _, err = c.digests(platformRef)
+			if err != nil {
+				return nil, err
+			}
 		}
+		subjects = append(subjects, intoto.Subject{
+			Name:        platformRef,
+			Digest:      intoto.DigestSet(digests),
+			Annotations: map[string]string{PlatformAnnotation: m.Platform.String()},
+		})
 	}
 
 	return subjects, nil
 }
 
-// ShaSum256HexEncoded calculates a SHA256 checksum from the content
-func ShaSum256HexEncoded(b []byte) string {
-	sha := sha256.Sum256(b)
-	shaHex := hex.EncodeToString(sha[:])
+// matchesPlatform checks if the platform satisfies one of the requested platforms. Without requested
+// platforms all platforms match, except the unknown/unknown platform used for attestation manifests.
+func (c *ContainerSubjecter) matchesPlatform(p v1.Platform) bool {
+	if len(c.platforms) == 0 {
+		return p.OS != "unknown" && p.Architecture != "unknown"
+	}
+	for _, spec := range c.platforms {
+		if p.Satisfies(spec) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *ContainerSubjecter) registryDigestOnly() bool {
+	return len(c.algorithms) == 0 || (len(c.algorithms) == 1 && c.algorithms[0] == digest.SHA256)
+}
+
+// digests retrieves the manifest digest of the image reference. When other digest algorithms than the registry
+// digest are requested, the manifest is fetched and hashed using all algorithms in a single pass.
+func (c *ContainerSubjecter) digests(ref string) (map[string]string, string, error) {
+	if c.registryDigestOnly() {
+		manifestDigest, err := c.source.digest(ref)
+		if err != nil {
+			return nil, "", err
+		}
+		alg, value, err := digest.Parse(manifestDigest)
+		if err != nil {
+			return nil, "", err
+		}
+		return map[string]string{alg: value}, manifestDigest, nil
+	}
+
+	manifest, _, err := c.source.manifest(ref)
+	if err != nil {
+		return nil, "", err
+	}
+	digests, err := digest.FromBytes(manifest, c.algorithms...)
+	if err != nil {
+		return nil, "", err
+	}
+	return digests, fmt.Sprintf("%s:%s", digest.SHA256, digest.SHA256Hex(manifest)), nil
+}
+
+// manifestSource retrieves image manifests by tag or digest reference
+type manifestSource interface {
+	// digest returns the digest of the manifest, e.g. sha256:<hex>
+	digest(ref string) (string, error)
+	// manifest returns the raw manifest and its media type
+	manifest(ref string) ([]byte, types.MediaType, error)
+}
+
+// registrySource retrieves the image manifests from the registry
+type registrySource struct {
+	options []crane.Option
+}
 
-	return shaHex
+func (s registrySource) digest(ref string) (string, error) {
+	return crane.Digest(ref, s.options...)
+}
+
+func (s registrySource) manifest(ref string) ([]byte, types.MediaType, error) {
+	desc, err := crane.Get(ref, s.options...)
+	if err != nil {
+		return nil, "", err
+	}
+	return desc.Manifest, desc.MediaType, nil
 }
@@ -3,11 +3,21 @@ package oci
 import (
 	"context"
 	"fmt"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/philips-labs/slsa-provenance-action/pkg/digest"
 	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
 )
 
@@ -44,7 +54,7 @@ func TestSubjects(t *testing.T) {
 			repo:   repo,
 			tags:   []string{"v0.4.0"},
 			digest: "sha256:284b471a878add368bf02a7935fa099024576c029491bcefaeb87f81efa093a4",
-			err:    "did not get expected digest, got sha256:194b471a878add368bf02a7935fa099024576c029491bcefaeb87f81efa093a3, expected sha256:284b471a878add368bf02a7935fa099024576c029491bcefaeb87f81efa093a4",
+			err:    "did not get expected digest sha256:284b471a878add368bf02a7935fa099024576c029491bcefaeb87f81efa093a4 for ghcr.io/philips-labs/slsa-provenance:v0.4.0 (got sha256:194b471a878add368bf02a7935fa099024576c029491bcefaeb87f81efa093a3)",
 		},
 	}
 
@@ -101,3 +111,170 @@ func assertSubject(assert *assert.Assertions, subject []intoto.Subject, repo, ta
 	digestValue := strings.Split(digest, ":")[1]
 	assert.Contains(subject, intoto.Subject{Name: subjectName, Digest: intoto.DigestSet{"sha256": digestValue}})
 }
+
+// pushRandomImage starts an in-memory registry and pushes a random image, returning the repository
+func pushRandomImage(t *testing.T, tags ...string) (string, v1.Image) {
+	s := httptest.NewServer(registry.New())
+	t.Cleanup(s.Close)
+
+	repo := fmt.Sprintf("%s/philips-labs/slsa-provenance", strings.TrimPrefix(s.URL, "http://"))
+	img, err := random.Image(1024, 1)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	for _, tag := range tags {
+		if !assert.NoError(t, crane.Push(img, fmt.Sprintf("%s:%s", repo, tag), crane.Insecure)) {
+			t.FailNow()
+		}
+	}
+	return repo, img
+}
+
+func TestSubjectsDigestAlgorithms(t *testing.T) {
+	assert := assert.New(t)
+
+	repo, img := pushRandomImage(t, "v0.1.0")
+	manifest, err := img.RawManifest()
+	assert.NoError(err)
+	expected, err := digest.FromBytes(manifest, digest.SHA256, digest.SHA512)
+	assert.NoError(err)
+	manifestDigest, err := img.Digest()
+	assert.NoError(err)
+
+	s, err := NewContainerSubjecter(repo, manifestDigest.String(), []string{"v0.1.0"}, crane.Insecure).Subjects()
+	assert.NoError(err)
+	assert.Equal([]intoto.Subject{{Name: repo + ":v0.1.0", Digest: intoto.DigestSet{"sha256": manifestDigest.Hex}}}, s)
+
+	s, err = NewContainerSubjecter(repo, manifestDigest.String(), []string{"v0.1.0"}, crane.Insecure).
+		WithDigestAlgorithms(digest.SHA256, digest.SHA512).
+		Subjects()
+	assert.NoError(err)
+	assert.Equal([]intoto.Subject{{Name: repo + ":v0.1.0", Digest: intoto.DigestSet(expected)}}, s)
+	assert.Equal(manifestDigest.Hex, s[0].Digest["sha256"])
+
+	_, err = NewContainerSubjecter(repo, "", []string{"v0.1.0"}, crane.Insecure).WithDigestAlgorithms("md5").Subjects()
+	assert.ErrorContains(err, `unsupported digest algorithm "md5"`)
+}
+
+func TestSubjectsPlatformManifests(t *testing.T) {
+	assert := assert.New(t)
+
+	s := httptest.NewServer(registry.New())
+	t.Cleanup(s.Close)
+	repo := fmt.Sprintf("%s/philips-labs/slsa-provenance", strings.TrimPrefix(s.URL, "http://"))
+
+	platforms := []v1.Platform{
+		{OS: "linux", Architecture: "amd64"},
+		{OS: "linux", Architecture: "arm64", Variant: "v8"},
+		{OS: "unknown", Architecture: "unknown"},
+	}
+	var idx v1.ImageIndex = empty.Index
+	images := make([]v1.Image, len(platforms))
+	for i := range platforms {
+		img, err := random.Image(1024, 1)
+		if !assert.NoError(err) {
+			return
+		}
+		images[i] = img
+		idx = mutate.AppendManifests(idx, mutate.IndexAddendum{Add: img, Descriptor: v1.Descriptor{Platform: &platforms[i]}})
+	}
+	ref, err := name.ParseReference(repo+":v0.1.0", name.Insecure)
+	if !assert.NoError(err) {
+		return
+	}
+	if !assert.NoError(remote.WriteIndex(ref, idx)) {
+		return
+	}
+	assert.NoError(crane.Push(images[0], repo+":single", crane.Insecure))
+
+	idxDigest, err := idx.Digest()
+	assert.NoError(err)
+	amd64Digest, err := images[0].Digest()
+	assert.NoError(err)
+	arm64Digest, err := images[1].Digest()
+	assert.NoError(err)
+
+	indexSubject := intoto.Subject{Name: repo + ":v0.1.0", Digest: intoto.DigestSet{"sha256": idxDigest.Hex}}
+	amd64Subject := intoto.Subject{
+		Name:        repo + "@" + amd64Digest.String(),
+		Digest:      intoto.DigestSet{"sha256": amd64Digest.Hex},
+		Annotations: map[string]string{PlatformAnnotation: "linux/amd64"},
+	}
+	arm64Subject := intoto.Subject{
+		Name:        repo + "@" + arm64Digest.String(),
+		Digest:      intoto.DigestSet{"sha256": arm64Digest.Hex},
+		Annotations: map[string]string{PlatformAnnotation: "linux/arm64/v8"},
+	}
+
+	subjects, err := NewContainerSubjecter(repo, idxDigest.String(), []string{"v0.1.0"}, crane.Insecure).Subjects()
+	assert.NoError(err)
+	assert.Equal([]intoto.Subject{indexSubject}, subjects)
+
+	subjects, err = NewContainerSubjecter(repo, idxDigest.String(), []string{"v0.1.0"}, crane.Insecure).WithPlatformManifests().Subjects()
+	assert.NoError(err)
+	assert.Equal([]intoto.Subject{indexSubject, amd64Subject, arm64Subject}, subjects)
+
+	subjects, err = NewContainerSubjecter(repo, "", []string{"v0.1.0"}, crane.Insecure).
+		WithPlatformManifests(v1.Platform{OS: "linux", Architecture: "arm64"}).
+		Subjects()
+	assert.NoError(err)
+	assert.Equal([]intoto.Subject{indexSubject, arm64Subject}, subjects)
+
+	subjects, err = NewContainerSubjecter(repo, "", []string{"v0.1.0"}, crane.Insecure).
+		WithPlatformManifests(v1.Platform{OS: "linux", Architecture: "amd64"}).
+		WithDigestAlgorithms(digest.SHA256, digest.SHA512).
+		Subjects()
+	assert.NoError(err)
+	if assert.Len(subjects, 2) {
+		manifest, err := images[0].RawManifest()
+		assert.NoError(err)
+		expected, err := digest.FromBytes(manifest, digest.SHA256, digest.SHA512)
+		assert.NoError(err)
+		assert.Equal(intoto.DigestSet(expected), subjects[1].Digest)
+		assert.Equal(amd64Subject.Annotations, subjects[1].Annotations)
+	}
+
+	subjects, err = NewContainerSubjecter(repo, amd64Digest.String(), []string{"single"}, crane.Insecure).WithPlatformManifests().Subjects()
+	assert.NoError(err)
+	assert.Equal([]intoto.Subject{{Name: repo + ":single", Digest: intoto.DigestSet{"sha256": amd64Digest.Hex}}}, subjects)
+}
+
+func TestSubjectsPinnedDigest(t *testing.T) {
+	assert := assert.New(t)
+
+	repo, img := pushRandomImage(t, "v0.1.0", "latest")
+	other, err := random.Image(1024, 1)
+	assert.NoError(err)
+	assert.NoError(crane.Push(other, repo+":v0.2.0", crane.Insecure))
+	assert.NoError(crane.Push(other, repo+":v0.3.0", crane.Insecure))
+
+	imgDigest, err := img.Digest()
+	assert.NoError(err)
+	otherDigest, err := other.Digest()
+	assert.NoError(err)
+	pinned := fmt.Sprintf("%s@%s", repo, imgDigest)
+
+	s, err := NewContainerSubjecter(pinned, "", nil, crane.Insecure).Subjects()
+	assert.NoError(err)
+	assert.Equal([]intoto.Subject{{Name: pinned, Digest: intoto.DigestSet{"sha256": imgDigest.Hex}}}, s)
+
+	s, err = NewContainerSubjecter(pinned, imgDigest.String(), []string{"v0.1.0", "latest"}, crane.Insecure).Subjects()
+	assert.NoError(err)
+	assert.Equal([]intoto.Subject{
+		{Name: repo + ":v0.1.0", Digest: intoto.DigestSet{"sha256": imgDigest.Hex}},
+		{Name: repo + ":latest", Digest: intoto.DigestSet{"sha256": imgDigest.Hex}},
+	}, s)
+
+	_, err = NewContainerSubjecter(pinned, "", []string{"v0.1.0", "v0.2.0", "v0.3.0"}, crane.Insecure).Subjects()
+	assert.EqualError(err, fmt.Sprintf("did not get expected digest %s for %s:v0.2.0 (got %s), %s:v0.3.0 (got %s)", imgDigest, repo, otherDigest, repo, otherDigest))
+
+	_, err = NewContainerSubjecter(repo, imgDigest.String(), []string{"v0.1.0", "v0.2.0"}, crane.Insecure).Subjects()
+	assert.EqualError(err, fmt.Sprintf("did not get expected digest %s for %s:v0.2.0 (got %s)", imgDigest, repo, otherDigest))
+
+	_, err = NewContainerSubjecter(pinned, otherDigest.String(), nil, crane.Insecure).Subjects()
+	assert.EqualError(err, fmt.Sprintf("digest %s does not match the pinned digest %s", otherDigest, imgDigest))
+
+	missing := repo + "@sha256:284b471a878add368bf02a7935fa099024576c029491bcefaeb87f81efa093a4"
+	_, err = NewContainerSubjecter(missing, "", nil, crane.Insecure).Subjects()
+	assert.ErrorContains(err, "MANIFEST_UNKNOWN")
+}
@@ -0,0 +1,316 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+)
+
+const (
+	// RuleBuilderID the builder id must be one of the allowed builder ids
+	RuleBuilderID = "builder-id"
+	// RuleBuildType the buildType must be one of the allowed build types
+	RuleBuildType = "build-type"
+	// RuleMaterials the required materials must be present
+	RuleMaterials = "materials"
+	// RuleSourceRepo the source repository must be one of the allowed repositories
+	RuleSourceRepo = "source-repo"
+	// RuleSourceBranch the source ref must be one of the allowed branches
+	RuleSourceBranch = "source-branch"
+	// RuleMaxAge the build must have finished within the max age
+	RuleMaxAge = "max-age"
+	// RuleCompleteness the required completeness flags must be set
+	RuleCompleteness = "completeness"
+)
+
+// ErrPolicyViolated is returned when one or more policy rules are violated
+var ErrPolicyViolated = errors.New("provenance violates policy")
+
+// Policy declares the rules provenance has to comply with.
+//
+// Rules that are not set are not evaluated. The allow lists support path.Match patterns, e.g. release/*.
+type Policy struct {
+	BuilderIDs     []string     `yaml:"builderIds,omitempty"`
+	BuildTypes     []string     `yaml:"buildTypes,omitempty"`
+	Materials      []Material   `yaml:"materials,omitempty"`
+	SourceRepos    []string     `yaml:"sourceRepos,omitempty"`
+	SourceBranches []string     `yaml:"sourceBranches,omitempty"`
+	MaxAge         Duration     `yaml:"maxAge,omitempty"`
+	Completeness   Completeness `yaml:"completeness,omitempty"`
+}
+
+// Material a material required to be present in the provenance, matched by uri and/or digest
+type Material struct {
+	URI    string           `yaml:"uri,omitempty"`
+	Digest intoto.DigestSet `yaml:"digest,omitempty"`
+}
+
+// Completeness the completeness flags required to be claimed by the builder
+type Completeness struct {
+	Parameters  bool `yaml:"parameters,omitempty"`
+	Environment bool `yaml:"environment,omitempty"`
+	Materials   bool `yaml:"materials,omitempty"`
+}
+
+// Duration a time.Duration which can be unmarshalled from a string like 168h
+type Duration time.Duration
+
+// UnmarshalYAML parses the duration using time.ParseDuration
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	var s string
+	if err := value.Decode(&s); err != nil {
+		return err
+	}
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid maxAge: %w", err)
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+// MarshalYAML formats the duration as a string like 168h0m0s
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return time.Duration(d).String(), nil
+}
+
+// Violation a violated policy rule
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Result the outcome of evaluating a statement against a policy
+type Result struct {
+	Violations []Violation `json:"violations"`
+}
+
+// Allowed reports whether no rules were violated
+func (r *Result) Allowed() bool {
+	return len(r.Violations) == 0
+}
+
+// Err returns ErrPolicyViolated wrapped with all violations, nil when the statement is allowed
+func (r *Result) Err() error {
+	if r.Allowed() {
+		return nil
+	}
+
+	messages := make([]string, len(r.Violations))
+	for i, v := range r.Violations {
+		messages[i] = fmt.Sprintf("%s: %s", v.Rule, v.Message)
+	}
+	return fmt.Errorf("%w: %s", ErrPolicyViolated, strings.Join(messages, "; "))
+}
+
+func (r *Result) add(rule, format string, args ...interface{}) {
+	r.Violations = append(r.Violations, Violation{Rule: rule, Message: fmt.Sprintf(format, args...)})
+}
+
+// Load reads a policy from a YAML or JSON file
+func Load(path string) (*Policy, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+	p, err := Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse policy %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Parse parses a YAML or JSON policy (JSON being a subset of YAML), unknown fields are rejected to catch typos in rule names
+func Parse(content []byte) (*Policy, error) {
+	var p Policy
+	dec := yaml.NewDecoder(strings.NewReader(string(content)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&p); err != nil {
+		return nil, err
+	}
+	for i, m := range p.Materials {
+		if m.URI == "" && len(m.Digest) == 0 {
+			return nil, fmt.Errorf("material %d requires an uri or digest", i)
+		}
+	}
+	return &p, nil
+}
+
+// Evaluate checks the statement against all policy rules
+func (p *Policy) Evaluate(stmt *intoto.Statement) *Result {
+	return p.EvaluateAt(stmt, time.Now())
+}
+
+// EvaluateAt checks the statement against all policy rules, using now to evaluate the max age
+func (p *Policy) EvaluateAt(stmt *intoto.Statement, now time.Time) *Result {
+	r := &Result{Violations: []Violation{}}
+
+	if len(p.BuilderIDs) > 0 && !matchAny(p.BuilderIDs, stmt.BuilderID()) {
+		r.add(RuleBuilderID, "builder id %q is not allowed", stmt.BuilderID())
+	}
+	if len(p.BuildTypes) > 0 && !matchAny(p.BuildTypes, stmt.BuildType()) {
+		r.add(RuleBuildType, "build type %q is not allowed", stmt.BuildType())
+	}
+
+	materials := stmt.Materials()
+	for _, m := range p.Materials {
+		if !hasMaterial(materials, m) {
+			r.add(RuleMaterials, "required material %s is missing", m)
+		}
+	}
+
+	repo, ref := stmt.Source()
+	if len(p.SourceRepos) > 0 {
+		normalized := make([]string, len(p.SourceRepos))
+		for i, allowed := range p.SourceRepos {
+			normalized[i] = intoto.NormalizeRepoURI(allowed)
+		}
+		if !matchAny(normalized, intoto.NormalizeRepoURI(repo)) {
+			r.add(RuleSourceRepo, "source repository %q is not allowed", repo)
+		}
+	}
+	if len(p.SourceBranches) > 0 && !matchBranch(p.SourceBranches, ref) {
+		if ref == "" {
+			r.add(RuleSourceBranch, "source ref is not recorded in the provenance")
+		} else {
+			r.add(RuleSourceBranch, "source ref %q is not an allowed branch", ref)
+		}
+	}
+
+	if p.MaxAge > 0 {
+		evaluateMaxAge(r, stmt.FinishedOn(), time.Duration(p.MaxAge), now)
+	}
+
+	evaluateCompleteness(r, stmt, p.Completeness)
+
+	return r
+}
+
+func evaluateMaxAge(r *Result, finishedOn string, maxAge time.Duration, now time.Time) {
+	if finishedOn == "" {
+		r.add(RuleMaxAge, "build finished timestamp is not recorded in the provenance")
+		return
+	}
+	finished, err := time.Parse(time.RFC3339, finishedOn)
+	if err != nil {
+		r.add(RuleMaxAge, "invalid build finished timestamp %q", finishedOn)
+		return
+	}
+	if age := now.Sub(finished); age > maxAge {
+		r.add(RuleMaxAge, "build finished %s ago, which exceeds the max age of %s", age.Round(time.Second), maxAge)
+	}
+}
+
+func evaluateCompleteness(r *Result, stmt *intoto.Statement, required Completeness) {
+	if required == (Completeness{}) {
+		return
+	}
+	// NOTE: SLSA v1.0 dropped the completeness claims.
+	if stmt.PredicateV1 != nil {
+		r.add(RuleCompleteness, "completeness is not recorded in SLSA v1.0 provenance")
+		return
+	}
+
+	actual := stmt.Predicate.Metadata.Completeness
+	if required.Parameters && !actual.Parameters {
+		r.add(RuleCompleteness, "parameters are not claimed to be complete")
+	}
+	if required.Environment && !actual.Environment {
+		r.add(RuleCompleteness, "environment is not claimed to be complete")
+	}
+	if required.Materials && !actual.Materials {
+		r.add(RuleCompleteness, "materials are not claimed to be complete")
+	}
+}
+
+// String formats the material for use in violation messages
+func (m Material) String() string {
+	var parts []string
+	if m.URI != "" {
+		parts = append(parts, m.URI)
+	}
+	algs := make([]string, 0, len(m.Digest))
+	for alg := range m.Digest {
+		algs = append(algs, alg)
+	}
+	sort.Strings(algs)
+	for _, alg := range algs {
+		parts = append(parts, fmt.Sprintf("%s:%s", alg, m.Digest[alg]))
+	}
+	return strings.Join(parts, " ")
+}
+
+func hasMaterial(materials []intoto.Item, required Material) bool {
+	for _, m := range materials {
+		if required.URI != "" && !materialURIMatches(m.URI, required.URI) {
+			continue
+		}
+		if digestsContain(m.Digest, required.Digest) {
+			return true
+		}
+	}
+	return false
+}
+
+// materialURIMatches compares the material uris, a git uri without a ref like git+https://github.com/owner/repo
+// matches the repository at any ref, e.g. git+https://github.com/owner/repo@refs/heads/main
+func materialURIMatches(uri, required string) bool {
+	if uri == required {
+		return true
+	}
+	if !strings.HasPrefix(uri, "git+") || !strings.HasPrefix(required, "git+") {
+		return false
+	}
+
+	repo, ref := intoto.ParseSourceURI(uri)
+	requiredRepo, requiredRef := intoto.ParseSourceURI(required)
+	if intoto.NormalizeRepoURI(repo) != intoto.NormalizeRepoURI(requiredRepo) {
+		return false
+	}
+	return requiredRef == "" || ref == requiredRef
+}
+
+func digestsContain(actual, required intoto.DigestSet) bool {
+	for alg, digest := range required {
+		if !strings.EqualFold(actual[alg], digest) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if pattern == value {
+			return true
+		}
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchBranch matches the ref against the allowed branches, a branch like "main" matches refs/heads/main
+func matchBranch(branches []string, ref string) bool {
+	if ref == "" {
+		return false
+	}
+	for _, branch := range branches {
+		if !strings.HasPrefix(branch, "refs/") {
+			branch = "refs/heads/" + branch
+		}
+		if matchAny([]string{branch}, ref) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,205 @@
+package policy_test
+
+import (
+	"context"
+	"os"
+	"path"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/github"
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+	"github.com/philips-labs/slsa-provenance-action/pkg/policy"
+)
+
+const (
+	builderID = "https://github.com/philips-labs/slsa-provenance-action/Attestations/GitHubHostedActions@v1"
+	buildType = "https://github.com/Attestations/GitHubActionsWorkflow@v1"
+	repoURI   = "https://github.com/philips-labs/slsa-provenance-action"
+	sha       = "a3bc1c27230caa1cc3c27961f7e9cab43cd208dc"
+)
+
+func policyDir() string {
+	_, filename, _, _ := runtime.Caller(0)
+	return path.Join(path.Dir(filename), "../../test-data/policy")
+}
+
+func statement(ref string) *intoto.Statement {
+	return intoto.SLSAProvenanceStatement(
+		intoto.WithBuilder(builderID),
+		intoto.WithMetadata(repoURI+"/actions/runs/1"),
+		intoto.WithInvocation(buildType, "CI", nil, nil, []intoto.Item{
+			{URI: "git+" + repoURI + "@" + ref, Digest: intoto.DigestSet{"sha1": sha}},
+		}),
+	)
+}
+
+func violatedRules(r *policy.Result) []string {
+	rules := make([]string, len(r.Violations))
+	for i, v := range r.Violations {
+		rules[i] = v.Rule
+	}
+	return rules
+}
+
+func TestLoad(t *testing.T) {
+	assert := assert.New(t)
+
+	p, err := policy.Load(path.Join(policyDir(), "policy-valid.yaml"))
+	if assert.NoError(err) {
+		assert.Len(p.BuilderIDs, 2)
+		assert.Equal([]string{"main", "temp/*"}, p.SourceBranches)
+		assert.True(p.Completeness.Parameters)
+	}
+
+	p, err = policy.Load(path.Join(policyDir(), "policy-strict.json"))
+	if assert.NoError(err) {
+		assert.Equal(policy.Duration(time.Hour), p.MaxAge)
+		assert.Equal("git+https://github.com/philips-labs/other-repo", p.Materials[0].URI)
+	}
+
+	_, err = policy.Load(path.Join(policyDir(), "policy-unknown-rule.yaml"))
+	assert.ErrorContains(err, "field builderId not found")
+
+	_, err = policy.Load(path.Join(policyDir(), "non-existing.yaml"))
+	assert.ErrorContains(err, "failed to read policy:")
+
+	_, err = policy.Parse([]byte("maxAge: 1 week"))
+	assert.ErrorContains(err, "invalid maxAge")
+
+	_, err = policy.Parse([]byte("materials:\n  - {}"))
+	assert.EqualError(err, "material 0 requires an uri or digest")
+}
+
+func TestEvaluate(t *testing.T) {
+	assert := assert.New(t)
+
+	p, err := policy.Load(path.Join(policyDir(), "policy-valid.yaml"))
+	assert.NoError(err)
+
+	r := p.Evaluate(statement("refs/heads/temp/dump-context"))
+	assert.True(r.Allowed())
+	assert.NoError(r.Err())
+
+	r = p.Evaluate(statement("refs/heads/feature"))
+	assert.Equal([]string{policy.RuleSourceBranch}, violatedRules(r))
+	assert.EqualError(r.Err(), `provenance violates policy: source-branch: source ref "refs/heads/feature" is not an allowed branch`)
+}
+
+func TestEvaluateReportsAllViolations(t *testing.T) {
+	assert := assert.New(t)
+
+	p, err := policy.Load(path.Join(policyDir(), "policy-strict.json"))
+	assert.NoError(err)
+
+	stmt := statement("refs/heads/main")
+	finished, err := time.Parse(time.RFC3339, stmt.FinishedOn())
+	assert.NoError(err)
+
+	r := p.EvaluateAt(stmt, finished.Add(2*time.Hour))
+	assert.False(r.Allowed())
+	assert.Equal([]string{
+		policy.RuleBuilderID,
+		policy.RuleMaterials,
+		policy.RuleSourceRepo,
+		policy.RuleMaxAge,
+		policy.RuleCompleteness,
+	}, violatedRules(r))
+	assert.ErrorIs(r.Err(), policy.ErrPolicyViolated)
+
+	r = p.EvaluateAt(stmt, finished.Add(time.Minute))
+	assert.NotContains(violatedRules(r), policy.RuleMaxAge)
+}
+
+func TestEvaluateMaterials(t *testing.T) {
+	assert := assert.New(t)
+
+	stmt := statement("refs/heads/main")
+	stmt.Predicate.Materials = append(stmt.Predicate.Materials, intoto.Item{URI: "pkg:docker/alpine@3.15", Digest: intoto.DigestSet{"sha256": "abc"}})
+
+	p := &policy.Policy{Materials: []policy.Material{
+		{URI: "pkg:docker/alpine@3.15"},
+		{Digest: intoto.DigestSet{"sha1": sha}},
+		{URI: "pkg:docker/alpine@3.15", Digest: intoto.DigestSet{"sha256": "def"}},
+	}}
+	r := p.Evaluate(stmt)
+	if assert.Len(r.Violations, 1) {
+		assert.Equal("required material pkg:docker/alpine@3.15 sha256:def is missing", r.Violations[0].Message)
+	}
+}
+
+func TestEvaluateGeneratedProvenance(t *testing.T) {
+	assert := assert.New(t)
+
+	artifact := path.Join(t.TempDir(), "salsa.txt")
+	if err := os.WriteFile(artifact, []byte("salsa"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, version := range []string{intoto.PredicateVersionV02, intoto.PredicateVersionV1} {
+		env := &github.Environment{
+			Context: &github.Context{
+				Repository:      "philips-labs/slsa-provenance-action",
+				RepositoryOwner: "philips-labs",
+				RunID:           "1029384756",
+				Ref:             "refs/heads/main",
+				SHA:             sha,
+				Event:           []byte("{}"),
+			},
+			Runner:           &github.RunnerContext{},
+			PredicateVersion: version,
+		}
+		stmt, err := env.GenerateProvenanceStatement(context.Background(), intoto.NewFilePathSubjecter(artifact))
+		if !assert.NoError(err) {
+			return
+		}
+
+		p := &policy.Policy{Materials: []policy.Material{
+			{URI: "git+" + repoURI},
+			{URI: "git+" + repoURI + ".git"},
+		}}
+		assert.Empty(p.Evaluate(stmt).Violations, version)
+	}
+
+	env := &github.Environment{
+		Context:          &github.Context{Repository: "philips-labs/slsa-provenance-action", Ref: "refs/heads/main", SHA: sha, Event: []byte("{}")},
+		Runner:           &github.RunnerContext{},
+		PredicateVersion: intoto.PredicateVersionV1,
+	}
+	stmt, err := env.GenerateProvenanceStatement(context.Background(), intoto.NewFilePathSubjecter(artifact))
+	if !assert.NoError(err) {
+		return
+	}
+	p := &policy.Policy{Materials: []policy.Material{
+		{URI: "git+" + repoURI + "@refs/heads/main"},
+		{URI: "git+" + repoURI + "@refs/heads/develop"},
+	}}
+	r := p.Evaluate(stmt)
+	if assert.Len(r.Violations, 1) {
+		assert.Equal("required material git+"+repoURI+"@refs/heads/develop is missing", r.Violations[0].Message)
+	}
+}
+
+func TestEvaluateV1(t *testing.T) {
+	assert := assert.New(t)
+
+	stmt := intoto.SLSAProvenanceStatementV1(
+		intoto.WithBuildDefinition("https://slsa-framework.github.io/github-actions-buildtypes/workflow/v1", nil, nil, []intoto.ResourceDescriptor{
+			{URI: "git+" + repoURI + "@refs/heads/main", Digest: intoto.DigestSet{"gitCommit": sha}},
+		}),
+		intoto.WithRunDetails(builderID, repoURI+"/actions/runs/1"),
+	)
+
+	p := &policy.Policy{
+		BuilderIDs:     []string{builderID},
+		SourceRepos:    []string{repoURI + ".git"},
+		SourceBranches: []string{"refs/heads/main"},
+		MaxAge:         policy.Duration(time.Hour),
+		Completeness:   policy.Completeness{Environment: true},
+	}
+	r := p.Evaluate(stmt)
+	assert.Equal([]string{policy.RuleCompleteness}, violatedRules(r))
+}
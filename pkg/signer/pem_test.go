@@ -0,0 +1,117 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadPrivateKey(t *testing.T) {
+	password := func() string { return "test-password" }
+
+	testCases := []struct {
+		name     string
+		key      string
+		password PasswordRetriever
+		assert   func(assert *assert.Assertions, key interface{})
+	}{
+		{
+			name: "PKCS#8 ed25519",
+			key:  "ed25519.key",
+			assert: func(assert *assert.Assertions, key interface{}) {
+				assert.IsType(ed25519.PrivateKey{}, key)
+			},
+		},
+		{
+			name: "SEC 1 ECDSA",
+			key:  "ecdsa.key",
+			assert: func(assert *assert.Assertions, key interface{}) {
+				assert.IsType(&ecdsa.PrivateKey{}, key)
+			},
+		},
+		{
+			name: "PKCS#8 RSA",
+			key:  "rsa.key",
+			assert: func(assert *assert.Assertions, key interface{}) {
+				assert.IsType(&rsa.PrivateKey{}, key)
+			},
+		},
+		{
+			name:     "encrypted PKCS#8 ECDSA (aes-256-cbc, hmacWithSHA256)",
+			key:      "ecdsa-encrypted.key",
+			password: password,
+			assert: func(assert *assert.Assertions, key interface{}) {
+				assert.IsType(&ecdsa.PrivateKey{}, key)
+			},
+		},
+		{
+			name:     "encrypted PKCS#8 ed25519 (aes-128-cbc, hmacWithSHA1)",
+			key:      "ed25519-encrypted.key",
+			password: password,
+			assert: func(assert *assert.Assertions, key interface{}) {
+				assert.IsType(ed25519.PrivateKey{}, key)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			assert := assert.New(tt)
+
+			data, err := os.ReadFile(path.Join(keysDir(), tc.key))
+			if !assert.NoError(err) {
+				return
+			}
+			key, err := LoadPrivateKey(data, tc.password)
+			if assert.NoError(err) {
+				tc.assert(assert, key)
+			}
+		})
+	}
+}
+
+func TestLoadPrivateKeyErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	encrypted, err := os.ReadFile(path.Join(keysDir(), "ecdsa-encrypted.key"))
+	if !assert.NoError(err) {
+		return
+	}
+	plain, err := os.ReadFile(path.Join(keysDir(), "ecdsa.key"))
+	if !assert.NoError(err) {
+		return
+	}
+
+	key, err := LoadPrivateKey(encrypted, nil)
+	assert.EqualError(err, "private key is encrypted, but no password was provided")
+	assert.Nil(key)
+
+	key, err = LoadPrivateKey(encrypted, func() string { return "" })
+	assert.EqualError(err, "private key is encrypted, but no password was provided")
+	assert.Nil(key)
+
+	key, err = LoadPrivateKey(encrypted, func() string { return "wrong-password" })
+	assert.ErrorIs(err, ErrIncorrectPassword)
+	assert.Nil(key)
+
+	key, err = LoadPrivateKey([]byte("not a pem"), nil)
+	assert.EqualError(err, "no PEM block found")
+	assert.Nil(key)
+
+	pub, err := os.ReadFile(path.Join(keysDir(), "ecdsa.pub"))
+	if !assert.NoError(err) {
+		return
+	}
+	key, err = LoadPrivateKey(pub, nil)
+	assert.EqualError(err, `unsupported PEM block type "PUBLIC KEY"`)
+	assert.Nil(key)
+
+	key, err = LoadPrivateKey(plain, func() string { return "ignored" })
+	assert.NoError(err)
+	assert.NotNil(key)
+}
@@ -0,0 +1,196 @@
+package signer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+)
+
+const (
+	// PasswordEnvVar the environment variable holding the password of an encrypted signing key
+	PasswordEnvVar = "SLSA_SIGNING_KEY_PASSWORD"
+)
+
+// PasswordRetriever allows to implement a function to retrieve the password of an encrypted private key
+type PasswordRetriever func() string
+
+// Signer signs messages with a private key, e.g. the PAE of a DSSE envelope
+type Signer interface {
+	// KeyID identifies the key used to sign
+	KeyID() string
+	// Public returns the public key matching the private key used to sign
+	Public() crypto.PublicKey
+	// Sign signs the given message
+	Sign(message []byte) ([]byte, error)
+}
+
+// SignEnvelope signs the PAE of the envelope with each of the signers and attaches the signatures
+func SignEnvelope(env *intoto.Envelope, signers ...Signer) error {
+	pae, err := env.PAE()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range signers {
+		sig, err := s.Sign(pae)
+		if err != nil {
+			return fmt.Errorf("failed to sign envelope with key %s: %w", s.KeyID(), err)
+		}
+		env.Signatures = append(env.Signatures, intoto.Signature{
+			KeyID: s.KeyID(),
+			Sig:   base64.StdEncoding.EncodeToString(sig),
+		})
+	}
+
+	return nil
+}
+
+// NewSignerFromFile loads the PEM encoded private key at path and creates a Signer for it.
+// The passwordRetriever is only used when the private key is encrypted.
+func NewSignerFromFile(path string, passwordRetriever PasswordRetriever) (Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	key, err := LoadPrivateKey(data, passwordRetriever)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load signing key %s: %w", path, err)
+	}
+
+	return NewSigner(key)
+}
+
+// NewSigner creates a Signer for the given private key
+func NewSigner(key crypto.PrivateKey) (Signer, error) {
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		return NewED25519Signer(k)
+	case *ecdsa.PrivateKey:
+		return NewECDSASigner(k)
+	case *rsa.PrivateKey:
+		return NewRSAPSSSigner(k)
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+}
+
+// KeyID computes the key id as the hex encoded sha256 of the PKIX encoded public key
+func KeyID(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ED25519Signer implements Signer using an ed25519 private key
+type ED25519Signer struct {
+	key   ed25519.PrivateKey
+	keyID string
+}
+
+// NewED25519Signer creates a Signer for the given ed25519 private key
+func NewED25519Signer(key ed25519.PrivateKey) (*ED25519Signer, error) {
+	keyID, err := KeyID(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &ED25519Signer{key, keyID}, nil
+}
+
+// KeyID identifies the key used to sign
+func (s *ED25519Signer) KeyID() string {
+	return s.keyID
+}
+
+// Public returns the ed25519 public key
+func (s *ED25519Signer) Public() crypto.PublicKey {
+	return s.key.Public()
+}
+
+// Sign signs the message using ed25519
+func (s *ED25519Signer) Sign(message []byte) ([]byte, error) {
+	return ed25519.Sign(s.key, message), nil
+}
+
+// ECDSASigner implements Signer using an ECDSA P-256 private key
+type ECDSASigner struct {
+	key   *ecdsa.PrivateKey
+	keyID string
+}
+
+// NewECDSASigner creates a Signer for the given ECDSA private key, only the P-256 curve is supported
+func NewECDSASigner(key *ecdsa.PrivateKey) (*ECDSASigner, error) {
+	if key.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("unsupported ECDSA curve %s, only P-256 is supported", key.Curve.Params().Name)
+	}
+	keyID, err := KeyID(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &ECDSASigner{key, keyID}, nil
+}
+
+// KeyID identifies the key used to sign
+func (s *ECDSASigner) KeyID() string {
+	return s.keyID
+}
+
+// Public returns the ECDSA public key
+func (s *ECDSASigner) Public() crypto.PublicKey {
+	return s.key.Public()
+}
+
+// Sign signs the sha256 digest of the message and returns an ASN.1 encoded signature
+func (s *ECDSASigner) Sign(message []byte) ([]byte, error) {
+	digest := sha256.Sum256(message)
+	return ecdsa.SignASN1(rand.Reader, s.key, digest[:])
+}
+
+// RSAPSSSigner implements Signer using an RSA private key with the PSS signature scheme
+type RSAPSSSigner struct {
+	key   *rsa.PrivateKey
+	keyID string
+}
+
+// NewRSAPSSSigner creates a Signer for the given RSA private key
+func NewRSAPSSSigner(key *rsa.PrivateKey) (*RSAPSSSigner, error) {
+	if key.N.BitLen() < 2048 {
+		return nil, errors.New("unsupported RSA key size, at least 2048 bits are required")
+	}
+	keyID, err := KeyID(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &RSAPSSSigner{key, keyID}, nil
+}
+
+// KeyID identifies the key used to sign
+func (s *RSAPSSSigner) KeyID() string {
+	return s.keyID
+}
+
+// Public returns the RSA public key
+func (s *RSAPSSSigner) Public() crypto.PublicKey {
+	return s.key.Public()
+}
+
+// Sign signs the sha256 digest of the message using RSA-PSS
+func (s *RSAPSSSigner) Sign(message []byte) ([]byte, error) {
+	digest := sha256.Sum256(message)
+	return rsa.SignPSS(rand.Reader, s.key, crypto.SHA256, digest[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+}
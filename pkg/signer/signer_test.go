@@ -0,0 +1,139 @@
+package signer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"path"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+)
+
+func keysDir() string {
+	_, filename, _, _ := runtime.Caller(0)
+	return path.Join(path.Dir(filename), "../../test-data/keys")
+}
+
+func TestSigners(t *testing.T) {
+	message := []byte("DSSEv1 28 application/vnd.in-toto+json 2 {}")
+
+	testCases := []struct {
+		name   string
+		key    string
+		verify func(pub crypto.PublicKey, sig []byte) bool
+	}{
+		{
+			name: "ed25519",
+			key:  "ed25519.key",
+			verify: func(pub crypto.PublicKey, sig []byte) bool {
+				return ed25519.Verify(pub.(ed25519.PublicKey), message, sig)
+			},
+		},
+		{
+			name: "ecdsa",
+			key:  "ecdsa.key",
+			verify: func(pub crypto.PublicKey, sig []byte) bool {
+				digest := sha256.Sum256(message)
+				return ecdsa.VerifyASN1(pub.(*ecdsa.PublicKey), digest[:], sig)
+			},
+		},
+		{
+			name: "rsa-pss",
+			key:  "rsa.key",
+			verify: func(pub crypto.PublicKey, sig []byte) bool {
+				digest := sha256.Sum256(message)
+				return rsa.VerifyPSS(pub.(*rsa.PublicKey), crypto.SHA256, digest[:], sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			assert := assert.New(tt)
+
+			s, err := NewSignerFromFile(path.Join(keysDir(), tc.key), nil)
+			if !assert.NoError(err) {
+				return
+			}
+
+			keyID, err := KeyID(s.Public())
+			assert.NoError(err)
+			assert.Equal(keyID, s.KeyID())
+			assert.Len(s.KeyID(), 64)
+
+			sig, err := s.Sign(message)
+			assert.NoError(err)
+			assert.True(tc.verify(s.Public(), sig))
+			assert.False(tc.verify(s.Public(), append([]byte{0}, sig...)))
+		})
+	}
+}
+
+func TestSignEnvelope(t *testing.T) {
+	assert := assert.New(t)
+
+	ed, err := NewSignerFromFile(path.Join(keysDir(), "ed25519.key"), nil)
+	assert.NoError(err)
+	ec, err := NewSignerFromFile(path.Join(keysDir(), "ecdsa.key"), nil)
+	assert.NoError(err)
+
+	env, err := intoto.NewEnvelope(intoto.SLSAProvenanceStatement())
+	if !assert.NoError(err) {
+		return
+	}
+
+	err = SignEnvelope(env, ed, ec)
+	if !assert.NoError(err) || !assert.Len(env.Signatures, 2) {
+		return
+	}
+
+	pae, err := env.PAE()
+	assert.NoError(err)
+
+	assert.Equal(ed.KeyID(), env.Signatures[0].KeyID)
+	sig, err := base64.StdEncoding.DecodeString(env.Signatures[0].Sig)
+	assert.NoError(err)
+	assert.True(ed25519.Verify(ed.Public().(ed25519.PublicKey), pae, sig))
+
+	assert.Equal(ec.KeyID(), env.Signatures[1].KeyID)
+	sig, err = base64.StdEncoding.DecodeString(env.Signatures[1].Sig)
+	assert.NoError(err)
+	digest := sha256.Sum256(pae)
+	assert.True(ecdsa.VerifyASN1(ec.Public().(*ecdsa.PublicKey), digest[:], sig))
+
+	env.Payload = "not base64!"
+	assert.Error(SignEnvelope(env, ed))
+}
+
+func TestNewSignerErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(err)
+	s, err := NewSigner(p384)
+	assert.EqualError(err, "unsupported ECDSA curve P-384, only P-256 is supported")
+	assert.Nil(s)
+
+	rsa1024, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.NoError(err)
+	s, err = NewSigner(rsa1024)
+	assert.EqualError(err, "unsupported RSA key size, at least 2048 bits are required")
+	assert.Nil(s)
+
+	s, err = NewSigner("not a key")
+	assert.EqualError(err, "unsupported private key type string")
+	assert.Nil(s)
+
+	s, err = NewSignerFromFile(path.Join(keysDir(), "non-existing.key"), nil)
+	assert.EqualError(err, "failed to read signing key: open "+path.Join(keysDir(), "non-existing.key")+": no such file or directory")
+	assert.Nil(s)
+}
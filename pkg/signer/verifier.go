@@ -0,0 +1,138 @@
+package signer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+)
+
+var (
+	// ErrInvalidSignature is returned when a signature doesn't match the message
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrNoValidSignature is returned when none of the envelope signatures could be verified
+	ErrNoValidSignature = errors.New("no valid signature found for the given public keys")
+)
+
+// Verifier verifies signatures using a public key
+type Verifier interface {
+	// KeyID identifies the public key used to verify
+	KeyID() string
+	// Public returns the public key used to verify
+	Public() crypto.PublicKey
+	// Verify verifies the signature of the given message
+	Verify(message, sig []byte) error
+}
+
+// VerifyEnvelope verifies the envelope signatures against the PAE of the envelope.
+//
+// The key ids of the verifiers that verified a signature are returned, at least one signature has to verify.
+func VerifyEnvelope(env *intoto.Envelope, verifiers ...Verifier) ([]string, error) {
+	pae, err := env.PAE()
+	if err != nil {
+		return nil, err
+	}
+
+	var keyIDs []string
+	for _, v := range verifiers {
+		for _, s := range env.Signatures {
+			if s.KeyID != "" && s.KeyID != v.KeyID() {
+				continue
+			}
+			sig, err := base64.StdEncoding.DecodeString(s.Sig)
+			if err != nil {
+				continue
+			}
+			if v.Verify(pae, sig) == nil {
+				keyIDs = append(keyIDs, v.KeyID())
+				break
+			}
+		}
+	}
+
+	if len(keyIDs) == 0 {
+		return nil, ErrNoValidSignature
+	}
+
+	return keyIDs, nil
+}
+
+// NewVerifierFromFile loads the PEM encoded public key at path and creates a Verifier for it.
+func NewVerifierFromFile(path string) (Verifier, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %w", err)
+	}
+
+	pub, err := LoadPublicKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load public key %s: %w", path, err)
+	}
+
+	return NewVerifier(pub)
+}
+
+// NewVerifier creates a Verifier for the given public key
+func NewVerifier(pub crypto.PublicKey) (Verifier, error) {
+	switch k := pub.(type) {
+	case ed25519.PublicKey:
+		return newVerifier(k, func(message, sig []byte) bool {
+			return ed25519.Verify(k, message, sig)
+		})
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("unsupported ECDSA curve %s, only P-256 is supported", k.Curve.Params().Name)
+		}
+		return newVerifier(k, func(message, sig []byte) bool {
+			digest := sha256.Sum256(message)
+			return ecdsa.VerifyASN1(k, digest[:], sig)
+		})
+	case *rsa.PublicKey:
+		return newVerifier(k, func(message, sig []byte) bool {
+			digest := sha256.Sum256(message)
+			return rsa.VerifyPSS(k, crypto.SHA256, digest[:], sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil
+		})
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
+
+type publicKeyVerifier struct {
+	pub    crypto.PublicKey
+	keyID  string
+	verify func(message, sig []byte) bool
+}
+
+func newVerifier(pub crypto.PublicKey, verify func(message, sig []byte) bool) (*publicKeyVerifier, error) {
+	keyID, err := KeyID(pub)
+	if err != nil {
+		return nil, err
+	}
+	return &publicKeyVerifier{pub, keyID, verify}, nil
+}
+
+// KeyID identifies the public key used to verify
+func (v *publicKeyVerifier) KeyID() string {
+	return v.keyID
+}
+
+// Public returns the public key used to verify
+func (v *publicKeyVerifier) Public() crypto.PublicKey {
+	return v.pub
+}
+
+// Verify verifies the signature of the given message
+func (v *publicKeyVerifier) Verify(message, sig []byte) error {
+	if !v.verify(message, sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
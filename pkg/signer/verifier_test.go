@@ -0,0 +1,80 @@
+package signer
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+)
+
+func TestVerifyEnvelope(t *testing.T) {
+	keys := []string{"ed25519", "ecdsa", "rsa"}
+
+	for _, k := range keys {
+		t.Run(k, func(tt *testing.T) {
+			assert := assert.New(tt)
+
+			s, err := NewSignerFromFile(path.Join(keysDir(), k+".key"), nil)
+			if !assert.NoError(err) {
+				return
+			}
+			v, err := NewVerifierFromFile(path.Join(keysDir(), k+".pub"))
+			if !assert.NoError(err) {
+				return
+			}
+			assert.Equal(s.KeyID(), v.KeyID())
+
+			env, err := intoto.NewEnvelope(intoto.SLSAProvenanceStatement())
+			assert.NoError(err)
+			assert.NoError(SignEnvelope(env, s))
+
+			keyIDs, err := VerifyEnvelope(env, v)
+			assert.NoError(err)
+			assert.Equal([]string{v.KeyID()}, keyIDs)
+
+			env.Signatures[0].KeyID = ""
+			keyIDs, err = VerifyEnvelope(env, v)
+			assert.NoError(err)
+			assert.Equal([]string{v.KeyID()}, keyIDs)
+
+			env.Payload = env.Payload[:len(env.Payload)-4]
+			_, err = VerifyEnvelope(env, v)
+			assert.ErrorIs(err, ErrNoValidSignature)
+		})
+	}
+}
+
+func TestVerifyEnvelopeWrongKey(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := NewSignerFromFile(path.Join(keysDir(), "ed25519.key"), nil)
+	assert.NoError(err)
+	v, err := NewVerifierFromFile(path.Join(keysDir(), "ecdsa.pub"))
+	assert.NoError(err)
+
+	env, err := intoto.NewEnvelope(intoto.SLSAProvenanceStatement())
+	assert.NoError(err)
+	assert.NoError(SignEnvelope(env, s))
+
+	_, err = VerifyEnvelope(env, v)
+	assert.ErrorIs(err, ErrNoValidSignature)
+
+	env.Signatures = nil
+	_, err = VerifyEnvelope(env, v)
+	assert.ErrorIs(err, ErrNoValidSignature)
+}
+
+func TestNewVerifierErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := NewVerifierFromFile(path.Join(keysDir(), "non-existing.pub"))
+	assert.ErrorContains(err, "failed to read public key:")
+
+	_, err = NewVerifierFromFile(path.Join(keysDir(), "ed25519.key"))
+	assert.EqualError(err, `failed to load public key `+path.Join(keysDir(), "ed25519.key")+`: unsupported PEM block type "PRIVATE KEY"`)
+
+	_, err = NewVerifier("not-a-key")
+	assert.EqualError(err, "unsupported public key type string")
+}
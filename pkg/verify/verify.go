@@ -0,0 +1,210 @@
+package verify
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+	"github.com/philips-labs/slsa-provenance-action/pkg/signer"
+)
+
+const (
+	// CheckSignature verifies the envelope signatures using the given public keys
+	CheckSignature = "signature"
+	// CheckSubjects verifies the recomputed subject digests match the statement subjects
+	CheckSubjects = "subjects"
+	// CheckBuilderID verifies the builder id of the predicate
+	CheckBuilderID = "builder-id"
+	// CheckSourceRepo verifies the source repository the build was executed from
+	CheckSourceRepo = "source-repo"
+	// CheckSourceRef verifies the source ref the build was executed from
+	CheckSourceRef = "source-ref"
+)
+
+// ErrVerificationFailed is returned when one or more checks failed
+var ErrVerificationFailed = errors.New("provenance verification failed")
+
+// Options the expectations to verify the provenance envelope against.
+//
+// Checks for empty options are skipped, except for the signature check which requires at least one Verifier.
+type Options struct {
+	Verifiers  []signer.Verifier
+	Subjecter  intoto.Subjecter
+	BuilderID  string
+	SourceRepo string
+	SourceRef  string
+}
+
+// CheckResult the outcome of a single verification check
+type CheckResult struct {
+	Check  string `json:"check"`
+	Passed bool   `json:"passed"`
+	Reason string `json:"reason"`
+}
+
+// Result the outcome of all verification checks
+type Result struct {
+	Statement *intoto.Statement `json:"-"`
+	Checks    []CheckResult     `json:"checks"`
+}
+
+// Passed reports whether all checks passed
+func (r *Result) Passed() bool {
+	return len(r.Failures()) == 0
+}
+
+// Failures returns the checks that failed
+func (r *Result) Failures() []CheckResult {
+	var failures []CheckResult
+	for _, c := range r.Checks {
+		if !c.Passed {
+			failures = append(failures, c)
+		}
+	}
+	return failures
+}
+
+// Err returns ErrVerificationFailed wrapped with the failed checks, nil when all checks passed
+func (r *Result) Err() error {
+	failures := r.Failures()
+	if len(failures) == 0 {
+		return nil
+	}
+
+	reasons := make([]string, len(failures))
+	for i, f := range failures {
+		reasons[i] = fmt.Sprintf("%s: %s", f.Check, f.Reason)
+	}
+	return fmt.Errorf("%w: %s", ErrVerificationFailed, strings.Join(reasons, "; "))
+}
+
+func (r *Result) add(check string, passed bool, reason string, args ...interface{}) {
+	r.Checks = append(r.Checks, CheckResult{Check: check, Passed: passed, Reason: fmt.Sprintf(reason, args...)})
+}
+
+// Envelope verifies the signatures of the envelope and the wrapped statement against the given options.
+//
+// An error is only returned when the envelope can't be decoded, failed checks are reported in the Result.
+func Envelope(env *intoto.Envelope, opts Options) (*Result, error) {
+	stmt, err := env.Statement()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Result{Statement: stmt}
+	verifySignatures(r, env, opts.Verifiers)
+
+	if opts.Subjecter != nil {
+		verifySubjects(r, stmt, opts.Subjecter)
+	}
+	if opts.BuilderID != "" {
+		builderID := stmt.BuilderID()
+		r.add(CheckBuilderID, builderID == opts.BuilderID, "expected %q, got %q", opts.BuilderID, builderID)
+	}
+
+	repo, ref := stmt.Source()
+	if opts.SourceRepo != "" {
+		r.add(CheckSourceRepo, intoto.NormalizeRepoURI(repo) == intoto.NormalizeRepoURI(opts.SourceRepo), "expected %q, got %q", opts.SourceRepo, repo)
+	}
+	if opts.SourceRef != "" {
+		if ref == "" {
+			r.add(CheckSourceRef, false, "source ref is not recorded in the provenance")
+		} else {
+			r.add(CheckSourceRef, intoto.MatchRef(ref, opts.SourceRef), "expected %q, got %q", opts.SourceRef, ref)
+		}
+	}
+
+	return r, nil
+}
+
+func verifySignatures(r *Result, env *intoto.Envelope, verifiers []signer.Verifier) {
+	if len(verifiers) == 0 {
+		r.add(CheckSignature, false, "no public keys given to verify the signatures")
+		return
+	}
+	if len(env.Signatures) == 0 {
+		r.add(CheckSignature, false, "envelope is not signed")
+		return
+	}
+
+	keyIDs, err := signer.VerifyEnvelope(env, verifiers...)
+	if err != nil {
+		r.add(CheckSignature, false, "%s", err)
+		return
+	}
+	r.add(CheckSignature, true, "verified signature(s) of key(s) %s", strings.Join(keyIDs, ", "))
+}
+
+func verifySubjects(r *Result, stmt *intoto.Statement, subjecter intoto.Subjecter) {
+	subjects, err := subjecter.Subjects()
+	if err != nil {
+		r.add(CheckSubjects, false, "failed to compute subjects: %s", err)
+		return
+	}
+	if len(subjects) == 0 {
+		r.add(CheckSubjects, false, "no subjects found to verify")
+		return
+	}
+
+	var mismatches []string
+	for _, s := range subjects {
+		if reason := matchSubject(stmt.Subject, s); reason != "" {
+			mismatches = append(mismatches, reason)
+		}
+	}
+
+	if len(mismatches) > 0 {
+		r.add(CheckSubjects, false, "%s", strings.Join(mismatches, ", "))
+		return
+	}
+	r.add(CheckSubjects, true, "%d subject(s) match the provenance", len(subjects))
+}
+
+// matchSubject finds the subject by name and compares the digests. When the name isn't found,
+// any subject with a matching digest is accepted. Returns the reason of the mismatch or an empty string.
+func matchSubject(subjects []intoto.Subject, s intoto.Subject) string {
+	for _, candidate := range subjects {
+		if candidate.Name != s.Name {
+			continue
+		}
+		alg, ok := digestsMatch(candidate.Digest, s.Digest)
+		if ok {
+			return ""
+		}
+		if alg == "" {
+			return fmt.Sprintf("no common digest algorithm for %s", s.Name)
+		}
+		return fmt.Sprintf("%s digest mismatch for %s, expected %s, got %s", alg, s.Name, candidate.Digest[alg], s.Digest[alg])
+	}
+
+	for _, candidate := range subjects {
+		if _, ok := digestsMatch(candidate.Digest, s.Digest); ok {
+			return ""
+		}
+	}
+	return fmt.Sprintf("subject %s not found in provenance", s.Name)
+}
+
+// digestsMatch compares all digest algorithms both sets have in common. It returns the first mismatching
+// algorithm, or when all match the first common algorithm. No common algorithm is considered a mismatch.
+func digestsMatch(expected, actual intoto.DigestSet) (string, bool) {
+	algs := make([]string, 0, len(actual))
+	for alg := range actual {
+		if _, ok := expected[alg]; ok {
+			algs = append(algs, alg)
+		}
+	}
+	if len(algs) == 0 {
+		return "", false
+	}
+	sort.Strings(algs)
+
+	for _, alg := range algs {
+		if !strings.EqualFold(expected[alg], actual[alg]) {
+			return alg, false
+		}
+	}
+	return algs[0], true
+}
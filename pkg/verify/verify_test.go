@@ -0,0 +1,167 @@
+package verify_test
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/philips-labs/slsa-provenance-action/pkg/intoto"
+	"github.com/philips-labs/slsa-provenance-action/pkg/signer"
+	"github.com/philips-labs/slsa-provenance-action/pkg/verify"
+)
+
+const (
+	builderID = "https://github.com/philips-labs/slsa-provenance-action/Attestations/GitHubHostedActions@v1"
+	repoURI   = "https://github.com/philips-labs/slsa-provenance-action"
+)
+
+type staticSubjecter []intoto.Subject
+
+func (s staticSubjecter) Subjects() ([]intoto.Subject, error) {
+	return s, nil
+}
+
+func rootDir() string {
+	_, filename, _, _ := runtime.Caller(0)
+	return path.Join(path.Dir(filename), "../..")
+}
+
+func signedEnvelope(t *testing.T, subjects []intoto.Subject) *intoto.Envelope {
+	stmt := intoto.SLSAProvenanceStatement(
+		intoto.WithSubject(subjects),
+		intoto.WithBuilder(builderID),
+		intoto.WithInvocation("https://github.com/Attestations/GitHubActionsWorkflow@v1", "CI", nil, nil, []intoto.Item{
+			{URI: "git+" + repoURI + "@refs/tags/v0.8.0", Digest: intoto.DigestSet{"sha1": "a3bc1c27230caa1cc3c27961f7e9cab43cd208dc"}},
+		}),
+	)
+	env, err := intoto.NewEnvelope(stmt)
+	assert.NoError(t, err)
+
+	s, err := signer.NewSignerFromFile(path.Join(rootDir(), "test-data/keys/ed25519.key"), nil)
+	assert.NoError(t, err)
+	assert.NoError(t, signer.SignEnvelope(env, s))
+
+	return env
+}
+
+func TestEnvelope(t *testing.T) {
+	subjects := []intoto.Subject{
+		{Name: "salty.tar.gz", Digest: intoto.DigestSet{"sha256": "f5e3c2a7b5bb24a1d1ebe1e1b8e40dc4d2c7a1c1c8b7df2e4e8c1ad1cd1ac5b4"}},
+	}
+	env := signedEnvelope(t, subjects)
+
+	ed25519, err := signer.NewVerifierFromFile(path.Join(rootDir(), "test-data/keys/ed25519.pub"))
+	assert.NoError(t, err)
+	ecdsa, err := signer.NewVerifierFromFile(path.Join(rootDir(), "test-data/keys/ecdsa.pub"))
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		opts     verify.Options
+		failures []string
+	}{
+		{
+			name: "all checks pass",
+			opts: verify.Options{
+				Verifiers:  []signer.Verifier{ed25519},
+				Subjecter:  staticSubjecter(subjects),
+				BuilderID:  builderID,
+				SourceRepo: "github.com/philips-labs/slsa-provenance-action",
+				SourceRef:  "v0.8.0",
+			},
+		},
+		{
+			name: "subject renamed but digest matches",
+			opts: verify.Options{
+				Verifiers: []signer.Verifier{ed25519},
+				Subjecter: staticSubjecter{{Name: "renamed.tar.gz", Digest: subjects[0].Digest}},
+			},
+		},
+		{
+			name:     "no public keys",
+			opts:     verify.Options{},
+			failures: []string{verify.CheckSignature},
+		},
+		{
+			name:     "wrong public key",
+			opts:     verify.Options{Verifiers: []signer.Verifier{ecdsa}},
+			failures: []string{verify.CheckSignature},
+		},
+		{
+			name: "tampered artifact",
+			opts: verify.Options{
+				Verifiers: []signer.Verifier{ed25519},
+				Subjecter: staticSubjecter{{Name: "salty.tar.gz", Digest: intoto.DigestSet{"sha256": "0000"}}},
+			},
+			failures: []string{verify.CheckSubjects},
+		},
+		{
+			name: "unexpected builder, repo and ref",
+			opts: verify.Options{
+				Verifiers:  []signer.Verifier{ed25519},
+				BuilderID:  "https://example.com/builder",
+				SourceRepo: "github.com/philips-labs/other",
+				SourceRef:  "refs/heads/v0.8.0",
+			},
+			failures: []string{verify.CheckBuilderID, verify.CheckSourceRepo, verify.CheckSourceRef},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			assert := assert.New(tt)
+
+			result, err := verify.Envelope(env, tc.opts)
+			if !assert.NoError(err) {
+				return
+			}
+
+			var failures []string
+			for _, f := range result.Failures() {
+				failures = append(failures, f.Check)
+			}
+			assert.Equal(tc.failures, failures)
+			assert.Equal(len(tc.failures) == 0, result.Passed())
+			if len(tc.failures) == 0 {
+				assert.NoError(result.Err())
+			} else {
+				assert.ErrorIs(result.Err(), verify.ErrVerificationFailed)
+			}
+		})
+	}
+}
+
+func TestEnvelopeSourceRefNotRecorded(t *testing.T) {
+	assert := assert.New(t)
+
+	stmt := intoto.SLSAProvenanceStatement(
+		intoto.WithBuilder(builderID),
+		intoto.WithInvocation("https://github.com/Attestations/GitHubActionsWorkflow@v1", "CI", nil, nil, []intoto.Item{
+			{URI: "git+" + repoURI, Digest: intoto.DigestSet{"sha1": "a3bc1c27230caa1cc3c27961f7e9cab43cd208dc"}},
+		}),
+	)
+	env, err := intoto.NewEnvelope(stmt)
+	if !assert.NoError(err) {
+		return
+	}
+
+	result, err := verify.Envelope(env, verify.Options{SourceRepo: repoURI, SourceRef: "main"})
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal([]verify.CheckResult{
+		{Check: verify.CheckSignature, Passed: false, Reason: "no public keys given to verify the signatures"},
+		{Check: verify.CheckSourceRepo, Passed: true, Reason: fmt.Sprintf("expected %q, got %q", repoURI, repoURI)},
+		{Check: verify.CheckSourceRef, Passed: false, Reason: "source ref is not recorded in the provenance"},
+	}, result.Checks)
+}
+
+func TestEnvelopeUnsupportedPayload(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := verify.Envelope(&intoto.Envelope{PayloadType: "text/plain"}, verify.Options{})
+	assert.Error(err)
+}